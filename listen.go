@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/ftl/rigproxy/pkg/config"
+)
+
+// openListeners opens the listeners on the given address. Unix domain sockets are created with the given file mode,
+// if it is not 0. Sockets passed by systemd are taken from the given activated listeners.
+func openListeners(address string, mode os.FileMode, activated map[string][]net.Listener) ([]net.Listener, error) {
+	switch {
+	case strings.HasPrefix(address, config.SystemdPrefix):
+		name := strings.TrimPrefix(address, config.SystemdPrefix)
+		listeners, ok := activated[name]
+		if !ok {
+			return nil, fmt.Errorf("no socket %q passed by systemd", name)
+		}
+		delete(activated, name)
+		return listeners, nil
+	case strings.HasPrefix(address, config.UnixPrefix):
+		l, err := listenUnix(strings.TrimPrefix(address, config.UnixPrefix), mode)
+		if err != nil {
+			return nil, err
+		}
+		return []net.Listener{l}, nil
+	default:
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, err
+		}
+		return []net.Listener{l}, nil
+	}
+}
+
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	// remove the socket that was left over by a previous instance
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		err = os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode == 0 {
+		return l, nil
+	}
+	err = os.Chmod(path, mode)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -2,101 +2,522 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"io"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	flag "github.com/spf13/pflag"
 
+	"github.com/ftl/rigproxy/pkg/admin"
 	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/config"
 	"github.com/ftl/rigproxy/pkg/netio"
 	"github.com/ftl/rigproxy/pkg/protocol"
 	"github.com/ftl/rigproxy/pkg/proxy"
+	"github.com/ftl/rigproxy/pkg/systemd"
 )
 
 var (
-	destination = flag.StringP("destination", "d", "localhost:4534", "<host:port> of the destination rigctld server (default: localhost:4534)")
-	listen      = flag.StringP("listen", "l", ":4532", "listening address of this proxy (default: :4532)")
-	lifetime    = flag.DurationP("lifetime", "L", 200*time.Millisecond, "the lifetime of responses in the cache (default: 200ms)")
-	timeout     = flag.DurationP("timeout", "t", 10*time.Second, "the timeout for network requests")
-	retry       = flag.DurationP("retry", "r", 10*time.Second, "the retry interval")
-	trace       = flag.BoolP("trace", "v", false, "trace the communication with the destination")
-	test        = flag.BoolP("test", "T", false, "run test code")
+	configFile         = flag.StringP("config", "c", "", "the configuration file, the other flags are ignored if set (default: none)")
+	destination        = flag.StringP("destination", "d", "localhost:4534", "<host:port> of the destination rigctld server (default: localhost:4534)")
+	listen             = flag.StringP("listen", "l", ":4532", "listening address of this proxy, unix:<path> for a Unix domain socket, systemd:<name> for a socket passed by systemd (default: :4532)")
+	socketMode         = flag.String("socket-mode", "", "the file permissions of Unix domain sockets in octal notation, e.g. 0660 (default: umask)")
+	adminListen        = flag.String("admin", "", "listening address of the local admin interface, unix:<path> for a Unix domain socket (default: disabled)")
+	rotatorDestination = flag.String("rotator-destination", "", "<host:port> of the destination rotctld server, empty to disable the rotator proxy (default: disabled)")
+	rotatorListen      = flag.String("rotator-listen", ":4533", "listening address of the rotator proxy (default: :4533)")
+	fanOut             = flag.StringSlice("fan-out", nil, "answer the given commands from one get_rig_info request, e.g. get_freq,get_mode,get_vfo,get_split_vfo,get_ptt (default: disabled)")
+	lifetime           = flag.DurationP("lifetime", "L", 200*time.Millisecond, "the lifetime of responses in the cache (default: 200ms)")
+	timeout            = flag.DurationP("timeout", "t", 10*time.Second, "the timeout for network requests")
+	retry              = flag.DurationP("retry", "r", 10*time.Second, "the retry interval")
+	trace              = flag.BoolP("trace", "v", false, "trace the communication with the destination")
+	test               = flag.BoolP("test", "T", false, "run test code")
 )
 
 func main() {
 	flag.Parse()
 
+	if *test {
+		runTest()
+		return
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal("cannot load the configuration", "error", err)
+	}
+	logFile, err := openLog(cfg.Logging, nil)
+	if err != nil {
+		fatal("cannot open the log file", "error", err)
+	}
+	acl, err := cfg.ProxyACL()
+	if err != nil {
+		fatal("invalid ACL", "error", err)
+	}
+
+	activated, err := systemd.Listeners()
+	if err != nil {
+		fatal("cannot get the sockets passed by systemd", "error", err)
+	}
+
+	shared := &sharedState{
+		acl:      proxy.NewSharedACL(acl),
+		auth:     proxy.NewAuthenticator(cfg.Auth.Tokens...),
+		sessions: proxy.NewRegistry(),
+		trace:    new(atomic.Bool),
+		status:   newServiceStatus(),
+	}
+	shared.trace.Store(cfg.Logging.Trace)
+
+	upstreams := make([]*upstream, len(cfg.Upstreams))
+	for i, upstreamConfig := range cfg.Upstreams {
+		upstreams[i], err = newUpstream(upstreamConfig, cfg, shared)
+		if err != nil {
+			fatal("cannot create the upstream", "upstream", upstreamConfig.Name, "error", err)
+		}
+		err = upstreams[i].listen(activated)
+		if err != nil {
+			fatal("cannot listen", "upstream", upstreamConfig.Name, "error", err)
+		}
+	}
+	if cfg.Admin.Listen != "" {
+		err = serveAdmin(cfg.Admin, shared.sessions, upstreams, activated)
+		if err != nil {
+			fatal("cannot start the admin interface", "error", err)
+		}
+	}
+	for name := range activated {
+		slog.Warn("socket passed by systemd is not used", "name", name)
+	}
+	for _, u := range upstreams {
+		go u.run()
+	}
+
+	notify(systemd.Ready)
+	watchdog := watchdogTicks()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
 	for {
-		if *test {
-			runTest()
+		var sig os.Signal
+		select {
+		case <-watchdog:
+			notify(systemd.Watchdog)
+			continue
+		case sig = <-signals:
+		}
+
+		if sig != syscall.SIGHUP {
+			slog.Info("shutting down", "signal", sig)
+			notify(systemd.Stopping)
+			for _, u := range upstreams {
+				u.close()
+			}
 			return
 		}
 
-		loop()
-		<-time.After(*retry)
+		if *configFile == "" {
+			slog.Warn("SIGHUP ignored, no configuration file")
+			continue
+		}
+		newCfg, err := config.Load(*configFile)
+		if err != nil {
+			slog.Error("cannot reload the configuration, keeping the current configuration", "error", err)
+			continue
+		}
+		acl, err := newCfg.ProxyACL()
+		if err != nil {
+			slog.Error("cannot reload the configuration, keeping the current configuration", "error", err)
+			continue
+		}
+		logFile, err = openLog(newCfg.Logging, logFile)
+		if err != nil {
+			slog.Error("cannot reopen the log file", "error", err)
+		}
+
+		shared.acl.Store(acl)
+		shared.auth.SetTokens(newCfg.Auth.Tokens...)
+		shared.trace.Store(newCfg.Logging.Trace)
+		if newCfg.Logging.Trace != cfg.Logging.Trace {
+			shared.sessions.SetTraceAll(newCfg.Logging.Trace)
+		}
+		for _, u := range upstreams {
+			u.applyCacheConfig(newCfg)
+		}
+		if cfg.RequiresRestart(newCfg) {
+			slog.Warn("changes of upstreams, listeners, timeout or retry interval require a restart")
+		}
+		cfg = newCfg
+		slog.Info("configuration reloaded", "file", *configFile)
+	}
+}
+
+func loadConfig() (config.Config, error) {
+	if *configFile != "" {
+		return config.Load(*configFile)
+	}
+
+	result := config.Default()
+	result.Upstreams = []config.Upstream{
+		{Name: "rig", Type: config.RigUpstream, Destination: *destination, Listen: []string{*listen}, SocketMode: *socketMode, FanOut: *fanOut},
 	}
+	if *rotatorDestination != "" {
+		result.Upstreams = append(result.Upstreams, config.Upstream{
+			Name: "rotator", Type: config.RotatorUpstream, Destination: *rotatorDestination, Listen: []string{*rotatorListen}, SocketMode: *socketMode,
+		})
+	}
+	result.Admin.Listen = *adminListen
+	result.Admin.SocketMode = *socketMode
+	result.Cache.Lifetime = *lifetime
+	result.Logging.Trace = *trace
+	result.Timeout = *timeout
+	result.Retry = *retry
+	return result, result.Validate()
 }
 
-func loop() {
-	done := make(chan struct{})
-	defer func() {
-		select {
-		case <-done:
-		default:
-			close(done)
+// openLog redirects the log output into the configured file. The previously opened file is closed, this allows to
+// rotate the log file by sending SIGHUP.
+func openLog(cfg config.Logging, previous *os.File) (*os.File, error) {
+	var f *os.File
+	var out io.Writer = os.Stderr
+	if cfg.File != "" {
+		var err error
+		f, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return previous, err
 		}
-		log.Println("loop done")
-	}()
+		out = f
+	}
 
-	out, err := net.Dial("tcp", *destination)
+	log.SetOutput(out)
+	switch cfg.Format {
+	case config.TextFormat:
+		slog.SetDefault(slog.New(slog.NewTextHandler(out, nil)))
+	case config.JSONFormat:
+		slog.SetDefault(slog.New(slog.NewJSONHandler(out, nil)))
+	}
+
+	if previous != nil {
+		previous.Close()
+	}
+	return f, nil
+}
+
+// serveAdmin opens the listener of the admin interface and serves it in the background.
+func serveAdmin(cfg config.Admin, sessions *proxy.Registry, upstreams []*upstream, activated map[string][]net.Listener) error {
+	mode, err := cfg.FileMode()
 	if err != nil {
-		log.Println(err)
-		return
+		return err
+	}
+	listeners, err := openListeners(cfg.Listen, mode, activated)
+	if err != nil {
+		return err
 	}
-	defer out.Close()
-	log.Printf("connected to %s", *destination)
 
-	trx := protocol.NewTransceiver(netio.WithTimeout(out, *timeout))
-	trx.WhenDone(func() {
-		log.Println("transceiver stopped")
-		close(done)
-	})
+	adminUpstreams := make([]admin.Upstream, len(upstreams))
+	for i, u := range upstreams {
+		adminUpstreams[i] = admin.Upstream{Name: u.config.Name, Cache: u.cache, Transverter: u.transverter}
+	}
+	server := admin.NewServer(sessions, adminUpstreams...)
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			err := server.Serve(l)
+			if err != nil {
+				slog.Error("admin interface stopped", "error", err)
+			}
+		}(l)
+	}
+	slog.Info("admin interface listening", "listen", cfg.Listen)
+	return nil
+}
 
-	cache := cache.NewWithLifetime(*lifetime)
+// fatal logs the given error and terminates rigproxy.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-	l, err := net.Listen("tcp", *listen)
+// notify sends the given states to systemd, if rigproxy runs as systemd notify service.
+func notify(states ...string) {
+	_, err := systemd.Notify(states...)
 	if err != nil {
-		log.Println(err)
-		return
+		slog.Warn("cannot notify systemd", "error", err)
+	}
+}
+
+// watchdogTicks returns the ticks at which the main loop notifies the systemd watchdog, or nil if the watchdog is not
+// enabled for this service. The watchdog only reports that the main loop is responsive, the connection state of the
+// upstreams is reported through the status.
+func watchdogTicks() <-chan time.Time {
+	interval, ok := systemd.WatchdogInterval()
+	if !ok {
+		return nil
+	}
+	return time.NewTicker(interval / 2).C
+}
+
+// serviceStatus reports the connection state of all upstreams to systemd.
+type serviceStatus struct {
+	lock      *sync.Mutex
+	names     []string
+	connected map[string]bool
+}
+
+func newServiceStatus() *serviceStatus {
+	return &serviceStatus{
+		lock:      new(sync.Mutex),
+		connected: make(map[string]bool),
+	}
+}
+
+func (s *serviceStatus) add(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.names = append(s.names, name)
+}
+
+func (s *serviceStatus) set(name string, connected bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.connected[name] = connected
+	states := make([]string, len(s.names))
+	for i, name := range s.names {
+		if s.connected[name] {
+			states[i] = name + ": connected"
+		} else {
+			states[i] = name + ": disconnected"
+		}
 	}
-	go func() {
-		<-done
+	notify(systemd.Status(strings.Join(states, ", ")))
+}
+
+// sharedState is shared by all upstreams and can be changed by reloading the configuration.
+type sharedState struct {
+	acl      *proxy.SharedACL
+	auth     *proxy.Authenticator
+	sessions *proxy.Registry
+	trace    *atomic.Bool
+	status   *serviceStatus
+}
+
+type upstream struct {
+	config      config.Upstream
+	cache       *cache.Cache
+	transverter *proxy.Transverter
+	proxyOpts   []proxy.Option
+	listenTLS   *tls.Config
+	dialTLS     *tls.Config
+	timeout     time.Duration
+	retry       time.Duration
+	shared      *sharedState
+	listeners   []net.Listener
+	session     atomic.Pointer[session]
+}
+
+// session is the current connection to the upstream server.
+type session struct {
+	trx  *protocol.Transceiver
+	done <-chan struct{}
+}
+
+func newUpstream(upstreamConfig config.Upstream, cfg config.Config, shared *sharedState) (*upstream, error) {
+	listenTLS, err := upstreamConfig.TLS.Config()
+	if err != nil {
+		return nil, err
+	}
+	dialTLS, err := upstreamConfig.DestinationTLS.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &upstream{
+		config:    upstreamConfig,
+		cache:     cache.New(),
+		proxyOpts: []proxy.Option{proxy.WithACL(shared.acl), proxy.WithAuthenticator(shared.auth), proxy.WithRegistry(shared.sessions, upstreamConfig.Name)},
+		listenTLS: listenTLS,
+		dialTLS:   dialTLS,
+		timeout:   cfg.Timeout,
+		retry:     cfg.Retry,
+		shared:    shared,
+	}
+	result.applyCacheConfig(cfg)
+	shared.status.add(upstreamConfig.Name)
+
+	if upstreamConfig.Type == config.RotatorUpstream {
+		result.proxyOpts = append(result.proxyOpts, proxy.WithRotatorProtocol())
+	}
+	if guard := upstreamConfig.ProxyTXGuard(); guard != nil {
+		result.proxyOpts = append(result.proxyOpts, proxy.WithTXGuard(guard))
+	}
+	if transverter := upstreamConfig.ProxyTransverter(); transverter != nil {
+		result.transverter = transverter
+		result.proxyOpts = append(result.proxyOpts, proxy.WithTransverter(transverter))
+	}
+	if len(upstreamConfig.FanOut) > 0 {
+		result.proxyOpts = append(result.proxyOpts, proxy.WithFanOut(proxy.NewRigInfoFanOut(upstreamConfig.FanOutKeys()...)))
+	}
+
+	return result, nil
+}
+
+func (u *upstream) applyCacheConfig(cfg config.Config) {
+	u.cache.SetLifetime(cfg.Cache.Lifetime)
+	u.cache.SetLifetimes(cfg.CacheLifetimes())
+}
+
+// listen opens all listeners of this upstream. The listeners stay open for the lifetime of the process, this is
+// required for sockets that are passed by systemd.
+func (u *upstream) listen(activated map[string][]net.Listener) error {
+	mode, err := u.config.FileMode()
+	if err != nil {
+		return err
+	}
+	for _, address := range u.config.Listen {
+		listeners, err := openListeners(address, mode, activated)
+		if err != nil {
+			u.close()
+			return err
+		}
+		for _, l := range listeners {
+			if u.listenTLS != nil {
+				l = tls.NewListener(l, u.listenTLS)
+			}
+			u.listeners = append(u.listeners, l)
+		}
+	}
+	return nil
+}
+
+func (u *upstream) close() {
+	for _, l := range u.listeners {
 		l.Close()
-	}()
+	}
+}
+
+func (u *upstream) run() {
+	for _, l := range u.listeners {
+		go u.serve(l)
+	}
+	for {
+		u.loop()
+		<-time.After(u.retry)
+	}
+}
 
+func (u *upstream) serve(l net.Listener) {
 	for {
 		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
 		if err != nil {
-			log.Println(err)
+			slog.Error("cannot accept connection", "upstream", u.config.Name, "error", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		s := u.session.Load()
+		if s == nil {
+			slog.Warn("not connected, closing client connection", "upstream", u.config.Name, "destination", u.config.Destination, "client", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		opts := append(slices.Clone(u.proxyOpts), proxy.WithCache(u.cache), proxy.WithDone(s.done), proxy.WithTrace(u.shared.trace.Load()))
+		go proxy.Start(conn, s.trx, opts...)
+	}
+}
+
+func (u *upstream) loop() {
+	done := make(chan struct{})
+	var doneOnce sync.Once
+	closeDone := func() {
+		doneOnce.Do(func() { close(done) })
+	}
+	defer func() {
+		closeDone()
+		slog.Info("loop done", "upstream", u.config.Name)
+	}()
+
+	out, err := u.dial()
+	if err != nil {
+		slog.Error("cannot connect", "upstream", u.config.Name, "destination", u.config.Destination, "error", err)
+		return
+	}
+	defer out.Close()
+	slog.Info("connected", "upstream", u.config.Name, "destination", u.config.Destination)
+
+	trx := protocol.NewTransceiver(netio.WithTimeout(out, u.timeout), protocol.WithLogger(slog.Default().With("upstream", u.config.Name)))
+	trx.WhenDone(func() {
+		slog.Info("transceiver stopped", "upstream", u.config.Name)
+		closeDone()
+	})
+
+	if u.config.Password != "" {
+		err := u.authenticate(trx)
+		if err != nil {
+			slog.Error("authentication failed", "upstream", u.config.Name, "error", err)
+			trx.Close()
 			return
 		}
+	}
+
+	// the responses of the previous connection may be outdated
+	u.cache.Flush()
+
+	u.session.Store(&session{trx: trx, done: done})
+	u.shared.status.set(u.config.Name, true)
+	defer func() {
+		u.session.Store(nil)
+		u.shared.status.set(u.config.Name, false)
+	}()
+
+	<-done
+}
+
+func (u *upstream) dial() (net.Conn, error) {
+	if u.dialTLS == nil {
+		return net.Dial("tcp", u.config.Destination)
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: u.timeout}, "tcp", u.config.Destination, u.dialTLS)
+}
+
+func (u *upstream) authenticate(trx *protocol.Transceiver) error {
+	ctx, cancel := context.WithTimeout(context.Background(), u.timeout)
+	defer cancel()
 
-		go proxy.NewCached(conn, trx, cache, done, *trace)
+	request := protocol.Request{Command: protocol.LongCommand("password"), Args: []string{u.config.Password}}
+	if u.config.Type == config.RotatorUpstream {
+		request.Command = protocol.RotatorLongCommand("password")
+	}
+	response, err := trx.Send(ctx, request)
+	if err != nil {
+		return err
 	}
+	return protocol.ResultError(request.Key(), response.Result)
 }
 
 func runTest() {
 	out, err := net.Dial("tcp", *destination)
 	if err != nil {
-		log.Println(err)
+		slog.Error("cannot connect", "destination", *destination, "error", err)
 		return
 	}
 	defer out.Close()
 
 	trx := protocol.NewTransceiver(out)
 	trx.WhenDone(func() {
-		log.Println("transceiver stopped")
+		slog.Info("transceiver stopped")
 	})
 
 	for {
@@ -105,9 +526,9 @@ func runTest() {
 			request := protocol.Request{Command: protocol.ShortCommand("f")}
 			startTime := time.Now()
 			response, err := trx.Send(context.Background(), request)
-			log.Printf("%v %v", response, time.Now().Sub(startTime))
+			slog.Info("polled frequency", "response", response, "duration", time.Now().Sub(startTime))
 			if err != nil {
-				log.Print("polling frequency failed: ", err)
+				slog.Error("polling frequency failed", "error", err)
 				return
 			}
 		}
@@ -0,0 +1,271 @@
+// Package admin provides the local control channel of rigproxy. The admin interface is a simple line based text
+// protocol: each line contains one command, the output of a command is terminated by a line containing either OK or
+// ERROR followed by the error message.
+//
+// Commands:
+//
+//	help                     list the available commands
+//	sessions                 list the connected clients
+//	cache [upstream]         dump the cache contents with the age of each entry
+//	flush [upstream [key]]   flush the cache or remove a single entry
+//	kick <id>                disconnect the client session with the given id
+//	trace <id> on|off        toggle tracing for the client session with the given id
+//	band [upstream [name]]   show or select the transverter band, off deselects it
+//	quit                     close the admin connection
+package admin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+	"github.com/ftl/rigproxy/pkg/proxy"
+)
+
+// Upstream gives the admin interface access to the cache and the transverter of an upstream. Transverter is nil if
+// the upstream has no transverter.
+type Upstream struct {
+	Name        string
+	Cache       *cache.Cache
+	Transverter *proxy.Transverter
+}
+
+// Server serves the admin interface.
+type Server struct {
+	sessions  *proxy.Registry
+	upstreams []Upstream
+}
+
+var errQuit = errors.New("quit")
+
+// NewServer creates a new admin server for the given client sessions and upstreams.
+func NewServer(sessions *proxy.Registry, upstreams ...Upstream) *Server {
+	return &Server{
+		sessions:  sessions,
+		upstreams: upstreams,
+	}
+}
+
+// Serve accepts admin connections on the given listener until the listener is closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		err := s.Execute(conn, line)
+		if err == errQuit {
+			fmt.Fprintln(conn, "OK")
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(conn, "ERROR %v\n", err)
+		} else {
+			fmt.Fprintln(conn, "OK")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Error("admin: cannot read command", "error", err)
+	}
+}
+
+// Execute executes the given command line and writes the output to the given writer.
+func (s *Server) Execute(w io.Writer, line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "help":
+		return s.help(w)
+	case "sessions":
+		return s.listSessions(w)
+	case "cache":
+		return s.dumpCache(w, args)
+	case "flush":
+		return s.flush(args)
+	case "kick":
+		return s.kick(args)
+	case "trace":
+		return s.trace(args)
+	case "band":
+		return s.band(w, args)
+	case "quit", "exit":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %q, try help", command)
+	}
+}
+
+func (s *Server) help(w io.Writer) error {
+	fmt.Fprintln(w, "help                     list the available commands")
+	fmt.Fprintln(w, "sessions                 list the connected clients")
+	fmt.Fprintln(w, "cache [upstream]         dump the cache contents with the age of each entry")
+	fmt.Fprintln(w, "flush [upstream [key]]   flush the cache or remove a single entry")
+	fmt.Fprintln(w, "kick <id>                disconnect the client session with the given id")
+	fmt.Fprintln(w, "trace <id> on|off        toggle tracing for the client session with the given id")
+	fmt.Fprintln(w, "band [upstream [name]]   show or select the transverter band, off deselects it")
+	fmt.Fprintln(w, "quit                     close the admin connection")
+	return nil
+}
+
+func (s *Server) listSessions(w io.Writer) error {
+	now := time.Now()
+	for _, session := range s.sessions.Sessions() {
+		lastRequest := "-"
+		if !session.LastRequest.IsZero() {
+			lastRequest = now.Sub(session.LastRequest).Round(time.Millisecond).String()
+		}
+		lastCommand := string(session.LastCommand)
+		if lastCommand == "" {
+			lastCommand = "-"
+		}
+		fmt.Fprintf(w, "id=%d upstream=%s addr=%s connected=%s requests=%d last_command=%s last_request=%s trace=%t\n",
+			session.ID,
+			session.Upstream,
+			session.RemoteAddr,
+			session.Connected.Format(time.RFC3339),
+			session.Requests,
+			lastCommand,
+			lastRequest,
+			session.Trace,
+		)
+	}
+	return nil
+}
+
+func (s *Server) dumpCache(w io.Writer, args []string) error {
+	upstreams, err := s.selectUpstreams(args)
+	if err != nil {
+		return err
+	}
+	for _, upstream := range upstreams {
+		for _, entry := range upstream.Cache.Entries() {
+			fmt.Fprintf(w, "upstream=%s key=%s age=%s expired=%t response=%q\n",
+				upstream.Name,
+				entry.Key,
+				entry.Age.Round(time.Millisecond),
+				entry.Expired,
+				strings.Join(entry.Response.Lines(), "\n"),
+			)
+		}
+	}
+	return nil
+}
+
+func (s *Server) flush(args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: flush [upstream [key]]")
+	}
+	upstreams, err := s.selectUpstreams(args)
+	if err != nil {
+		return err
+	}
+	for _, upstream := range upstreams {
+		if len(args) == 2 {
+			upstream.Cache.Invalidate(protocol.CommandKey(args[1]))
+		} else {
+			upstream.Cache.Flush()
+		}
+	}
+	return nil
+}
+
+func (s *Server) selectUpstreams(args []string) ([]Upstream, error) {
+	if len(args) == 0 {
+		return s.upstreams, nil
+	}
+	for _, upstream := range s.upstreams {
+		if upstream.Name == args[0] {
+			return []Upstream{upstream}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown upstream %q", args[0])
+}
+
+func (s *Server) kick(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: kick <id>")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid session id %q", args[0])
+	}
+	return s.sessions.Kick(id)
+}
+
+func (s *Server) trace(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: trace <id> on|off")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid session id %q", args[0])
+	}
+	switch args[1] {
+	case "on":
+		return s.sessions.SetTrace(id, true)
+	case "off":
+		return s.sessions.SetTrace(id, false)
+	default:
+		return fmt.Errorf("usage: trace <id> on|off")
+	}
+}
+
+func (s *Server) band(w io.Writer, args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf("usage: band [upstream [name|off]]")
+	}
+	upstreams, err := s.selectUpstreams(args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		transverter := upstreams[0].Transverter
+		if transverter == nil {
+			return fmt.Errorf("upstream %q has no transverter", upstreams[0].Name)
+		}
+		name := args[1]
+		if name == "off" {
+			name = ""
+		}
+		return transverter.Select(name)
+	}
+
+	for _, upstream := range upstreams {
+		if upstream.Transverter == nil {
+			continue
+		}
+		selected := upstream.Transverter.Selected()
+		if selected == "" {
+			selected = "-"
+		}
+		fmt.Fprintf(w, "upstream=%s band=%s\n", upstream.Name, selected)
+	}
+	return nil
+}
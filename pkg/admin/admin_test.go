@@ -0,0 +1,122 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+	"github.com/ftl/rigproxy/pkg/proxy"
+)
+
+type fixedTransceiver protocol.Response
+
+func (t fixedTransceiver) Send(context.Context, protocol.Request) (protocol.Response, error) {
+	return protocol.Response(t), nil
+}
+
+func TestSessions(t *testing.T) {
+	registry := newTestSession(t)
+	server := NewServer(registry)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, server.Execute(out, "sessions"))
+	assert.Regexp(t, `^id=1 upstream=rig addr=pipe connected=\S+ requests=1 last_command=get_freq last_request=\S+ trace=false\n$`, out.String())
+
+	require.NoError(t, server.Execute(out, "trace 1 on"))
+	assert.True(t, registry.Sessions()[0].Trace)
+
+	require.NoError(t, server.Execute(out, "kick 1"))
+	assert.Empty(t, registry.Sessions())
+
+	assert.Error(t, server.Execute(out, "kick 1"))
+	assert.Error(t, server.Execute(out, "kick one"))
+	assert.Error(t, server.Execute(out, "trace 1 maybe"))
+}
+
+func TestCache(t *testing.T) {
+	rigCache := cache.New()
+	rigCache.Put("get_freq", protocol.Response{Data: []string{"14074000"}, Result: "0"})
+	rigCache.Put("get_mode", protocol.Response{Data: []string{"USB", "3000"}, Result: "0"})
+	rotatorCache := cache.New()
+	rotatorCache.Put("get_pos", protocol.Response{Data: []string{"180.000000", "10.000000"}, Result: "0"})
+	server := NewServer(proxy.NewRegistry(), Upstream{Name: "rig", Cache: rigCache}, Upstream{Name: "rotator", Cache: rotatorCache})
+
+	out := new(bytes.Buffer)
+	require.NoError(t, server.Execute(out, "cache rig"))
+	assert.Regexp(t, `^upstream=rig key=get_freq age=\S+ expired=false response="14074000"\nupstream=rig key=get_mode age=\S+ expired=false response="USB\\n3000"\n$`, out.String())
+
+	require.NoError(t, server.Execute(out, "flush rig get_freq"))
+	assert.Len(t, rigCache.Entries(), 1)
+	require.NoError(t, server.Execute(out, "flush"))
+	assert.Empty(t, rigCache.Entries())
+	assert.Empty(t, rotatorCache.Entries())
+
+	assert.Error(t, server.Execute(out, "cache amp"))
+	assert.Error(t, server.Execute(out, "flush amp"))
+}
+
+func TestServe(t *testing.T) {
+	server := NewServer(proxy.NewRegistry())
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go server.Serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	_, err = conn.Write([]byte("sessions\nunknown\nquit\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	lines := make([]string, 0, 3)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{"OK\n", "ERROR unknown command \"unknown\", try help\n", "OK\n"}, lines)
+}
+
+func newTestSession(t *testing.T) *proxy.Registry {
+	t.Helper()
+	registry := proxy.NewRegistry()
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	trx := fixedTransceiver(protocol.Response{Data: []string{"14074000"}, Result: "0"})
+	proxy.New(server, trx, nil, false, proxy.WithRegistry(registry, "rig"))
+
+	_, err := client.Write([]byte("f\n"))
+	require.NoError(t, err)
+	response, err := bufio.NewReader(client).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "14074000\n", response)
+	return registry
+}
+
+func TestBand(t *testing.T) {
+	transverter := proxy.NewTransverter(proxy.FrequencyMapping{Name: "2m", From: 28000000, To: 30000000, Offset: 116000000, BandSwitch: true})
+	server := NewServer(proxy.NewRegistry(), Upstream{Name: "rig", Cache: cache.New(), Transverter: transverter}, Upstream{Name: "rotator", Cache: cache.New()})
+
+	out := new(bytes.Buffer)
+	require.NoError(t, server.Execute(out, "band rig 2m"))
+	assert.Equal(t, "2m", transverter.Selected())
+	require.NoError(t, server.Execute(out, "band"))
+	assert.Equal(t, "upstream=rig band=2m\n", out.String())
+	require.NoError(t, server.Execute(out, "band rig off"))
+	assert.Equal(t, "", transverter.Selected())
+
+	assert.Error(t, server.Execute(out, "band rig 70cm"))
+	assert.Error(t, server.Execute(out, "band rotator 2m"))
+}
@@ -0,0 +1,175 @@
+// Package bandplans provides the IARU bandplans of all three regions for the HF bands and 6m. The bandplan of region 1
+// is provided by the hamradio bandplan package, the bandplans of region 2 and 3 use the same types and follow the
+// band edges and the mode segments of the IARU Region 2 and Region 3 bandplans.
+package bandplans
+
+import (
+	"fmt"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+)
+
+// DefaultRegion is the IARU region that is used if no region is configured.
+const DefaultRegion = 1
+
+// ForRegion returns the bandplan of the given IARU region (1, 2 or 3).
+func ForRegion(region int) (bandplan.Bandplan, error) {
+	switch region {
+	case 1:
+		return bandplan.IARURegion1, nil
+	case 2:
+		return IARURegion2, nil
+	case 3:
+		return IARURegion3, nil
+	default:
+		return nil, fmt.Errorf("unknown IARU region %d", region)
+	}
+}
+
+// IARURegion2 is the bandplan for IARU Region 2 (the Americas).
+var IARURegion2 = bandplan.Bandplan{
+	bandplan.Band160m: band(bandplan.Band160m, 1800000, 2000000,
+		portion(bandplan.ModeDigital, 1800000, 1810000, 500),
+		portion(bandplan.ModeCW, 1810000, 1840000, 200),
+		portion(bandplan.ModeDigital, 1840000, 1850000, 2700),
+		portion(bandplan.ModePhone, 1850000, 1999000, 2700),
+		portion(bandplan.ModeBeacon, 1999000, 2000000, 200),
+	),
+	bandplan.Band80m: band(bandplan.Band80m, 3500000, 4000000,
+		portion(bandplan.ModeCW, 3500000, 3580000, 200),
+		portion(bandplan.ModeDigital, 3580000, 3600000, 500),
+		portion(bandplan.ModePhone, 3600000, 4000000, 2700),
+	),
+	bandplan.Band60m: band(bandplan.Band60m, 5351500, 5366500,
+		portion(bandplan.ModeDigital, 5351500, 5354000, 200),
+		portion(bandplan.ModePhone, 5354000, 5366000, 2700),
+		portion(bandplan.ModeDigital, 5366000, 5366500, 20),
+	),
+	bandplan.Band40m: band(bandplan.Band40m, 7000000, 7300000,
+		portion(bandplan.ModeCW, 7000000, 7035000, 200),
+		portion(bandplan.ModeDigital, 7035000, 7053000, 500),
+		portion(bandplan.ModePhone, 7053000, 7300000, 2700),
+	),
+	bandplan.Band30m: band(bandplan.Band30m, 10100000, 10150000,
+		portion(bandplan.ModeCW, 10100000, 10130000, 200),
+		portion(bandplan.ModeDigital, 10130000, 10150000, 500),
+	),
+	bandplan.Band20m: band(bandplan.Band20m, 14000000, 14350000,
+		portion(bandplan.ModeCW, 14000000, 14070000, 200),
+		portion(bandplan.ModeDigital, 14070000, 14099500, 500),
+		portion(bandplan.ModeBeacon, 14099500, 14100500, 200),
+		portion(bandplan.ModeDigital, 14100500, 14112000, 500),
+		portion(bandplan.ModePhone, 14112000, 14350000, 2700),
+	),
+	bandplan.Band17m: band(bandplan.Band17m, 18068000, 18168000,
+		portion(bandplan.ModeCW, 18068000, 18095000, 200),
+		portion(bandplan.ModeDigital, 18095000, 18109500, 500),
+		portion(bandplan.ModeBeacon, 18109500, 18110500, 200),
+		portion(bandplan.ModePhone, 18110500, 18168000, 2700),
+	),
+	bandplan.Band15m: band(bandplan.Band15m, 21000000, 21450000,
+		portion(bandplan.ModeCW, 21000000, 21070000, 200),
+		portion(bandplan.ModeDigital, 21070000, 21149500, 500),
+		portion(bandplan.ModeBeacon, 21149500, 21150500, 200),
+		portion(bandplan.ModePhone, 21150500, 21450000, 2700),
+	),
+	bandplan.Band12m: band(bandplan.Band12m, 24890000, 24990000,
+		portion(bandplan.ModeCW, 24890000, 24915000, 200),
+		portion(bandplan.ModeDigital, 24915000, 24929000, 500),
+		portion(bandplan.ModeBeacon, 24929000, 24931000, 200),
+		portion(bandplan.ModePhone, 24931000, 24990000, 2700),
+	),
+	bandplan.Band10m: band(bandplan.Band10m, 28000000, 29700000,
+		portion(bandplan.ModeCW, 28000000, 28070000, 200),
+		portion(bandplan.ModeDigital, 28070000, 28190000, 500),
+		portion(bandplan.ModeBeacon, 28190000, 28225000, 200),
+		portion(bandplan.ModePhone, 28225000, 29700000, 6000),
+	),
+	bandplan.Band6m: band(bandplan.Band6m, 50000000, 54000000,
+		portion(bandplan.ModeCW, 50000000, 50100000, 500),
+		portion(bandplan.ModePhone, 50100000, 50300000, 2700),
+		portion(bandplan.ModeDigital, 50300000, 50600000, 2700),
+		portion(bandplan.ModePhone, 50600000, 54000000, 20000),
+	),
+}
+
+// IARURegion3 is the bandplan for IARU Region 3 (Asia and the Pacific).
+var IARURegion3 = bandplan.Bandplan{
+	bandplan.Band160m: band(bandplan.Band160m, 1800000, 2000000,
+		portion(bandplan.ModeCW, 1800000, 1830000, 200),
+		portion(bandplan.ModeDigital, 1830000, 1840000, 500),
+		portion(bandplan.ModePhone, 1840000, 2000000, 2700),
+	),
+	bandplan.Band80m: band(bandplan.Band80m, 3500000, 3900000,
+		portion(bandplan.ModeCW, 3500000, 3535000, 200),
+		portion(bandplan.ModePhone, 3535000, 3570000, 2700),
+		portion(bandplan.ModeDigital, 3570000, 3600000, 500),
+		portion(bandplan.ModePhone, 3600000, 3900000, 2700),
+	),
+	bandplan.Band60m: band(bandplan.Band60m, 5351500, 5366500,
+		portion(bandplan.ModeDigital, 5351500, 5354000, 200),
+		portion(bandplan.ModePhone, 5354000, 5366000, 2700),
+		portion(bandplan.ModeDigital, 5366000, 5366500, 20),
+	),
+	bandplan.Band40m: band(bandplan.Band40m, 7000000, 7300000,
+		portion(bandplan.ModeCW, 7000000, 7025000, 200),
+		portion(bandplan.ModeDigital, 7025000, 7040000, 500),
+		portion(bandplan.ModePhone, 7040000, 7300000, 2700),
+	),
+	bandplan.Band30m: band(bandplan.Band30m, 10100000, 10150000,
+		portion(bandplan.ModeCW, 10100000, 10130000, 200),
+		portion(bandplan.ModeDigital, 10130000, 10150000, 500),
+	),
+	bandplan.Band20m: band(bandplan.Band20m, 14000000, 14350000,
+		portion(bandplan.ModeCW, 14000000, 14070000, 200),
+		portion(bandplan.ModeDigital, 14070000, 14099000, 500),
+		portion(bandplan.ModeBeacon, 14099000, 14101000, 200),
+		portion(bandplan.ModeDigital, 14101000, 14112000, 2700),
+		portion(bandplan.ModePhone, 14112000, 14350000, 2700),
+	),
+	bandplan.Band17m: band(bandplan.Band17m, 18068000, 18168000,
+		portion(bandplan.ModeCW, 18068000, 18095000, 200),
+		portion(bandplan.ModeDigital, 18095000, 18109000, 500),
+		portion(bandplan.ModeBeacon, 18109000, 18111000, 200),
+		portion(bandplan.ModePhone, 18111000, 18168000, 2700),
+	),
+	bandplan.Band15m: band(bandplan.Band15m, 21000000, 21450000,
+		portion(bandplan.ModeCW, 21000000, 21070000, 200),
+		portion(bandplan.ModeDigital, 21070000, 21149000, 500),
+		portion(bandplan.ModeBeacon, 21149000, 21151000, 200),
+		portion(bandplan.ModePhone, 21151000, 21450000, 2700),
+	),
+	bandplan.Band12m: band(bandplan.Band12m, 24890000, 24990000,
+		portion(bandplan.ModeCW, 24890000, 24915000, 200),
+		portion(bandplan.ModeDigital, 24915000, 24929000, 500),
+		portion(bandplan.ModeBeacon, 24929000, 24931000, 200),
+		portion(bandplan.ModePhone, 24931000, 24990000, 2700),
+	),
+	bandplan.Band10m: band(bandplan.Band10m, 28000000, 29700000,
+		portion(bandplan.ModeCW, 28000000, 28070000, 200),
+		portion(bandplan.ModeDigital, 28070000, 28190000, 500),
+		portion(bandplan.ModeBeacon, 28190000, 28200000, 200),
+		portion(bandplan.ModePhone, 28200000, 29700000, 6000),
+	),
+	bandplan.Band6m: band(bandplan.Band6m, 50000000, 54000000,
+		portion(bandplan.ModeCW, 50000000, 50100000, 500),
+		portion(bandplan.ModePhone, 50100000, 54000000, 20000),
+	),
+}
+
+func band(name bandplan.BandName, from, to hamradio.Frequency, portions ...bandplan.Portion) bandplan.Band {
+	return bandplan.Band{
+		Name:           name,
+		FrequencyRange: hamradio.FrequencyRange{From: from, To: to},
+		Portions:       portions,
+	}
+}
+
+func portion(mode bandplan.Mode, from, to, maxBandwidth hamradio.Frequency) bandplan.Portion {
+	return bandplan.Portion{
+		Mode:           mode,
+		MaxBandwidth:   maxBandwidth,
+		FrequencyRange: hamradio.FrequencyRange{From: from, To: to},
+	}
+}
@@ -0,0 +1,46 @@
+package bandplans
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForRegion(t *testing.T) {
+	for region := 1; region <= 3; region++ {
+		plan, err := ForRegion(region)
+		require.NoError(t, err)
+		assert.Equal(t, bandplan.Band20m, plan.ByFrequency(14074000).Name)
+	}
+
+	_, err := ForRegion(0)
+	assert.Error(t, err)
+	_, err = ForRegion(4)
+	assert.Error(t, err)
+}
+
+func TestRegionalBandEdges(t *testing.T) {
+	assert.Equal(t, bandplan.BandUnknown, bandplan.IARURegion1.ByFrequency(7250000).Name)
+	assert.Equal(t, bandplan.Band40m, IARURegion2.ByFrequency(7250000).Name)
+	assert.Equal(t, bandplan.Band40m, IARURegion3.ByFrequency(7250000).Name)
+
+	assert.Equal(t, bandplan.Band80m, IARURegion2.ByFrequency(3950000).Name)
+	assert.Equal(t, bandplan.BandUnknown, IARURegion3.ByFrequency(3950000).Name)
+}
+
+func TestPortionsCoverTheBands(t *testing.T) {
+	for name, plan := range map[string]bandplan.Bandplan{"region 2": IARURegion2, "region 3": IARURegion3} {
+		for _, band := range plan {
+			t.Run(name+" "+string(band.Name), func(t *testing.T) {
+				require.NotEmpty(t, band.Portions)
+				assert.Equal(t, band.From, band.Portions[0].From)
+				assert.Equal(t, band.To, band.Portions[len(band.Portions)-1].To)
+				for i := 1; i < len(band.Portions); i++ {
+					assert.Equal(t, band.Portions[i-1].To, band.Portions[i].From, "gap or overlap at portion #%d", i)
+				}
+			})
+		}
+	}
+}
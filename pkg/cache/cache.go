@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -8,9 +9,10 @@ import (
 )
 
 type Cache struct {
-	m        map[protocol.CommandKey]entry
-	mutex    *sync.RWMutex
-	lifetime time.Duration
+	m         map[protocol.CommandKey]entry
+	mutex     *sync.RWMutex
+	lifetime  time.Duration
+	lifetimes map[protocol.CommandKey]time.Duration
 }
 
 type entry struct {
@@ -48,7 +50,8 @@ func (c *Cache) Get(key protocol.CommandKey) (protocol.Response, bool) {
 	if !ok {
 		return protocol.Response{}, false
 	}
-	if c.lifetime > 0 && time.Since(e.timestamp) > c.lifetime {
+	lifetime := c.lifetimeOf(key)
+	if lifetime < 0 || (lifetime > 0 && time.Since(e.timestamp) > lifetime) {
 		return protocol.Response{}, false
 	}
 
@@ -61,3 +64,69 @@ func (c *Cache) Invalidate(key protocol.CommandKey) {
 
 	delete(c.m, key)
 }
+
+func (c *Cache) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.m = make(map[protocol.CommandKey]entry)
+}
+
+// Entry describes a cached response.
+type Entry struct {
+	Key      protocol.CommandKey
+	Response protocol.Response
+	Age      time.Duration
+	Expired  bool
+}
+
+// Entries returns all entries of the cache, ordered by their key. Expired entries are included.
+func (c *Cache) Entries() []Entry {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	now := time.Now()
+	result := make([]Entry, 0, len(c.m))
+	for key, e := range c.m {
+		age := now.Sub(e.timestamp)
+		lifetime := c.lifetimeOf(key)
+		result = append(result, Entry{
+			Key:      key,
+			Response: e.resp,
+			Age:      age,
+			Expired:  lifetime < 0 || (lifetime > 0 && age > lifetime),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// SetLifetime sets the default lifetime of all entries. A lifetime of 0 means that entries never expire,
+// a negative lifetime disables caching.
+func (c *Cache) SetLifetime(lifetime time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lifetime = lifetime
+}
+
+// SetLifetimes sets individual lifetimes for the given keys, overriding the default lifetime. All previously
+// set individual lifetimes are replaced.
+func (c *Cache) SetLifetimes(lifetimes map[protocol.CommandKey]time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.lifetimes = make(map[protocol.CommandKey]time.Duration, len(lifetimes))
+	for key, lifetime := range lifetimes {
+		c.lifetimes[key] = lifetime
+	}
+}
+
+func (c *Cache) lifetimeOf(key protocol.CommandKey) time.Duration {
+	if lifetime, ok := c.lifetimes[key]; ok {
+		return lifetime
+	}
+	return c.lifetime
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ftl/rigproxy/pkg/protocol"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEmptyCache(t *testing.T) {
@@ -32,6 +33,16 @@ func TestPutGetRoundtrip(t *testing.T) {
 	assert.Equal(t, resp, actual)
 }
 
+func TestFlush(t *testing.T) {
+	cache := New()
+	cache.Put(theCommand, protocol.Response{Result: "0"})
+
+	cache.Flush()
+	_, ok := cache.Get(theCommand)
+
+	assert.False(t, ok)
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	cache := New()
 	wg := new(sync.WaitGroup)
@@ -93,4 +104,48 @@ func TestLifetime(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestIndividualLifetimes(t *testing.T) {
+	cache := NewWithLifetime(time.Hour)
+	cache.SetLifetimes(map[protocol.CommandKey]time.Duration{
+		theCommand: 10 * time.Millisecond,
+		"uncached": -1,
+	})
+	resp := protocol.Response{Result: "0"}
+
+	cache.Put(theCommand, resp)
+	cache.Put("uncached", resp)
+	cache.Put("other", resp)
+	_, ok := cache.Get("uncached")
+	assert.False(t, ok, "uncached")
+
+	time.Sleep(10 * time.Millisecond)
+	_, ok = cache.Get(theCommand)
+	assert.False(t, ok, "individual lifetime")
+	_, ok = cache.Get("other")
+	assert.True(t, ok, "default lifetime")
+
+	cache.SetLifetime(time.Millisecond)
+	_, ok = cache.Get("other")
+	assert.False(t, ok, "changed default lifetime")
+}
+
 const theCommand = protocol.CommandKey("the_command")
+
+func TestEntries(t *testing.T) {
+	cache := NewWithLifetime(time.Hour)
+	cache.SetLifetimes(map[protocol.CommandKey]time.Duration{"get_ptt": -1})
+	cache.Put("get_mode", protocol.Response{Data: []string{"USB", "3000"}, Result: "0"})
+	cache.Put("get_freq", protocol.Response{Data: []string{"14074000"}, Result: "0"})
+	cache.Put("get_ptt", protocol.Response{Data: []string{"0"}, Result: "0"})
+
+	entries := cache.Entries()
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, protocol.CommandKey("get_freq"), entries[0].Key)
+	assert.Equal(t, []string{"14074000"}, entries[0].Response.Data)
+	assert.False(t, entries[0].Expired)
+	assert.Less(t, entries[0].Age, time.Hour)
+	assert.Equal(t, protocol.CommandKey("get_mode"), entries[1].Key)
+	assert.Equal(t, protocol.CommandKey("get_ptt"), entries[2].Key)
+	assert.True(t, entries[2].Expired)
+}
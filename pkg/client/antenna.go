@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+/*
+	Antenna
+*/
+
+// Antenna is the number of an antenna port of the connected radio, starting at 1. 0 means the currently selected antenna.
+type Antenna int
+
+const CurrentAntenna Antenna = 0
+
+// AntennaInfo describes the antenna selection of the connected radio.
+type AntennaInfo struct {
+	Current Antenna
+	TX      Antenna
+	RX      Antenna
+	Option  int
+}
+
+// Antenna returns the antenna selection of the connected radio.
+func (c *Conn) Antenna(ctx context.Context) (AntennaInfo, error) {
+	return c.AntennaInfo(ctx, CurrentAntenna)
+}
+
+// AntennaInfo returns the information about the given antenna of the connected radio.
+func (c *Conn) AntennaInfo(ctx context.Context, antenna Antenna) (AntennaInfo, error) {
+	response, err := c.get(ctx, "get_ant", strconv.Itoa(int(antenna)))
+	if err != nil {
+		return AntennaInfo{}, err
+	}
+	return parseAntennaInfo(response)
+}
+
+// OnAntenna wraps the given callback function into the ResponseHandler interface and translates the generic response to the antenna selection.
+func OnAntenna(callback func(AntennaInfo)) (ResponseHandler, string, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		info, err := parseAntennaInfo(r)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse antenna result", "error", err)
+			return
+		}
+		callback(info)
+	}), "get_ant", strconv.Itoa(int(CurrentAntenna))
+}
+
+// SetAntenna selects the given antenna on the connected radio. The meaning of the option depends on the radio, use 0 if unsure.
+func (c *Conn) SetAntenna(ctx context.Context, antenna Antenna, option int) error {
+	if antenna < 1 {
+		return fmt.Errorf("%w: invalid antenna %d", protocol.ErrArgumentOutOfDomain, antenna)
+	}
+	return c.Set(ctx, "set_ant", strconv.Itoa(int(antenna)), strconv.Itoa(option))
+}
+
+// parseAntennaInfo parses the response of get_ant: current antenna, option, TX antenna and RX antenna.
+func parseAntennaInfo(r protocol.Response) (AntennaInfo, error) {
+	if len(r.Data) < 4 {
+		return AntennaInfo{}, fmt.Errorf("hamlib: incomplete antenna result: %v", r.Data)
+	}
+	var result AntennaInfo
+	var err error
+	result.Current, err = parseAntenna(r.Data[0])
+	if err != nil {
+		return AntennaInfo{}, err
+	}
+	result.Option, err = strconv.Atoi(strings.TrimSpace(r.Data[1]))
+	if err != nil {
+		return AntennaInfo{}, err
+	}
+	result.TX, err = parseAntenna(r.Data[2])
+	if err != nil {
+		return AntennaInfo{}, err
+	}
+	result.RX, err = parseAntenna(r.Data[3])
+	if err != nil {
+		return AntennaInfo{}, err
+	}
+	return result, nil
+}
+
+// parseAntenna parses antennas like "ANT1" or "1". Unknown or unset antennas are returned as 0.
+func parseAntenna(s string) (Antenna, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	value := strings.TrimPrefix(fields[0], "ANT")
+	if value == "" || value[0] < '0' || value[0] > '9' {
+		return 0, nil
+	}
+	antenna, err := strconv.Atoi(value)
+	return Antenna(antenna), err
+}
+
+/*
+	Tuner
+*/
+
+const (
+	// DefaultTuneTimeout is the maximum time TuneAndWait waits for the SWR to settle.
+	DefaultTuneTimeout = 30 * time.Second
+	// tuneInterval is the interval between two SWR readings while waiting for the tuner.
+	tuneInterval = 300 * time.Millisecond
+	// swrTolerance is the maximum difference between two SWR readings that are considered equal.
+	swrTolerance = 0.05
+	// settledReadings is the number of consecutive equal SWR readings that indicate a settled tuner.
+	settledReadings = 3
+)
+
+// Tune starts the antenna tuner of the connected radio.
+func (c *Conn) Tune(ctx context.Context) error {
+	return c.VFOOperation(ctx, VFOOpTune)
+}
+
+// TunerEnabled indicates if the antenna tuner of the connected radio is enabled.
+func (c *Conn) TunerEnabled(ctx context.Context) (bool, error) {
+	return c.Func(ctx, FuncTuner)
+}
+
+// EnableTuner enables the antenna tuner of the connected radio.
+func (c *Conn) EnableTuner(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncTuner, true)
+}
+
+// DisableTuner disables the antenna tuner of the connected radio.
+func (c *Conn) DisableTuner(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncTuner, false)
+}
+
+// SWR returns the current SWR reading of the connected radio.
+func (c *Conn) SWR(ctx context.Context) (float64, error) {
+	return c.Level(ctx, LevelSWR)
+}
+
+// TuneAndWait starts the antenna tuner of the connected radio and polls the SWR until it settles. The settled SWR is
+// returned. Readings of 0 are ignored, since most radios only measure the SWR while transmitting. If the SWR does not
+// settle within DefaultTuneTimeout or the given context is done first, the context's error is returned.
+func (c *Conn) TuneAndWait(ctx context.Context) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTuneTimeout)
+	defer cancel()
+
+	err := c.Tune(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	detector := swrSettleDetector{tolerance: swrTolerance, required: settledReadings}
+	ticker := time.NewTicker(tuneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+
+		swr, err := c.SWR(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if detector.add(swr) {
+			return swr, nil
+		}
+	}
+}
+
+type swrSettleDetector struct {
+	tolerance float64
+	required  int
+	last      float64
+	count     int
+}
+
+// add a SWR reading and indicate if the SWR has settled.
+func (d *swrSettleDetector) add(swr float64) bool {
+	if swr <= 0 {
+		return false
+	}
+	if d.count > 0 && math.Abs(swr-d.last) <= d.tolerance {
+		d.count++
+	} else {
+		d.count = 1
+	}
+	d.last = swr
+	return d.count >= d.required
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestParseAntennaInfo(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		data     []string
+		expected AntennaInfo
+	}{
+		{"named", []string{"ANT2", "0", "ANT2", "ANT3"}, AntennaInfo{Current: 2, TX: 2, RX: 3}},
+		{"numbers", []string{"1", "1", "1", "1"}, AntennaInfo{Current: 1, TX: 1, RX: 1, Option: 1}},
+		{"unknown", []string{"ANT1", "0", "ANT_UNKNOWN", "NONE"}, AntennaInfo{Current: 1}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := parseAntennaInfo(protocol.Response{Data: tC.data, Result: "0"})
+			require.NoError(t, err)
+			assert.Equal(t, tC.expected, actual)
+		})
+	}
+
+	_, err := parseAntennaInfo(protocol.Response{Data: []string{"ANT1"}})
+	assert.Error(t, err)
+}
+
+func TestSWRSettleDetector(t *testing.T) {
+	detector := swrSettleDetector{tolerance: 0.05, required: 3}
+
+	readings := []struct {
+		swr     float64
+		settled bool
+	}{
+		{0, false},
+		{2.5, false},
+		{1.8, false},
+		{1.3, false},
+		{1.32, false},
+		{0, false},
+		{1.3, true},
+	}
+	for i, r := range readings {
+		assert.Equal(t, r.settled, detector.add(r.swr), "reading %d", i)
+	}
+}
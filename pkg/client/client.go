@@ -33,10 +33,13 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ftl/hamradio"
 	"github.com/ftl/hamradio/bandplan"
@@ -45,22 +48,102 @@ import (
 )
 
 // Conn represents the Hamlib client connection to a rigctld server.
+//
+// The requests of a Conn are sent through a priority queue: set commands are preferred over get commands, and get
+// commands are preferred over poll requests. Use WithDefaultPriority to change the priority of all requests of a
+// Conn, or WithPriority to change the priority of the requests of a single method call.
 type Conn struct {
-	address string
-	trx     *protocol.Transceiver
-	polling *polling
-	closed  chan struct{}
+	address   string
+	tlsConfig *tls.Config
+	trx       *protocol.Transceiver
+	polling   *polling
+	power     *powerConversion
+	closed    chan struct{}
+	logger    *slog.Logger
+	dialer    *net.Dialer
+	trxOpts   []protocol.TransceiverOption
+	priority  protocol.Priority
+	password  string
+}
+
+// Option configures optional features of a Conn.
+type Option func(*Conn)
+
+// WithLogger lets the Conn use the given logger. Without logger, slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Conn) {
+		c.logger = logger
+	}
+}
+
+// WithTLS connects to the server through TLS using the given configuration.
+func WithTLS(config *tls.Config) Option {
+	return func(c *Conn) {
+		c.tlsConfig = config
+	}
+}
+
+// WithDialer uses the given dialer to connect to the server, e.g. to set a connect timeout or the local address.
+func WithDialer(dialer *net.Dialer) Option {
+	return func(c *Conn) {
+		c.dialer = dialer
+	}
+}
+
+// WithRequestTimeout limits the time to wait for the response to each request. The timeout applies in addition to
+// the deadline of the context that is passed to the methods of the Conn.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Conn) {
+		c.trxOpts = append(c.trxOpts, protocol.WithRequestTimeout(timeout))
+	}
+}
+
+// WithMetrics reports the latency and the error of every request to the given Metrics.
+func WithMetrics(metrics protocol.Metrics) Option {
+	return func(c *Conn) {
+		c.trxOpts = append(c.trxOpts, protocol.WithMetrics(metrics))
+	}
+}
+
+// WithBandplan uses the given bandplan to keep the power conversion tables per band. Without bandplan, the bandplan of
+// IARU Region 1 is used.
+func WithBandplan(plan bandplan.Bandplan) Option {
+	return func(c *Conn) {
+		c.power.bandplan = plan
+	}
+}
+
+// WithDefaultPriority lets all requests of the Conn use the given priority in the send queue, unless the context
+// of a method call carries a priority set with WithPriority.
+func WithDefaultPriority(priority protocol.Priority) Option {
+	return func(c *Conn) {
+		c.priority = priority
+	}
 }
 
 // Open a client connection to the rigctld server at the given address. If address is empty, "localhost:4532" is used as default.
-func Open(address string) (*Conn, error) {
+func Open(address string, opts ...Option) (*Conn, error) {
 	if address == "" {
 		address = "localhost:4532"
 	}
+	return open(address, opts)
+}
+
+// OpenTLS opens a client connection to the rigctld server or rigproxy at the given address through TLS. If address
+// is empty, "localhost:4532" is used as default.
+func OpenTLS(address string, config *tls.Config, opts ...Option) (*Conn, error) {
+	return Open(address, append([]Option{WithTLS(config)}, opts...)...)
+}
 
+func open(address string, opts []Option) (*Conn, error) {
 	result := Conn{
 		address: address,
+		power:   newPowerConversion(),
 		closed:  make(chan struct{}),
+		dialer:  new(net.Dialer),
+	}
+	for _, opt := range opts {
+		opt(&result)
 	}
 
 	err := result.connect()
@@ -71,28 +154,68 @@ func Open(address string) (*Conn, error) {
 	return &result, nil
 }
 
+// openDedicated opens another connection to the same server with the same options, e.g. for requests that block the
+// connection until they are done. The new connection is authenticated with the password of this connection.
+func (c *Conn) openDedicated(ctx context.Context) (*Conn, error) {
+	result := &Conn{
+		address:   c.address,
+		tlsConfig: c.tlsConfig,
+		power:     c.power,
+		closed:    make(chan struct{}),
+		logger:    c.logger,
+		dialer:    c.dialer,
+		trxOpts:   c.trxOpts,
+		priority:  c.priority,
+	}
+	err := result.connect()
+	if err != nil {
+		return nil, err
+	}
+	if c.password != "" {
+		err = result.Authenticate(ctx, c.password)
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
 func (c *Conn) connect() error {
 	if c.trx != nil {
 		c.trx.Close()
 	}
 
-	out, err := net.Dial("tcp", c.address)
+	var out net.Conn
+	var err error
+	if c.tlsConfig != nil {
+		out, err = tls.DialWithDialer(c.dialer, "tcp", c.address, c.tlsConfig)
+	} else {
+		out, err = c.dialer.Dial("tcp", c.address)
+	}
 	if err != nil {
 		return fmt.Errorf("cannot open hamlib connection: %v", err)
 	}
-	log.Printf("connected to %s", c.address)
+	c.log().Info("connected", "address", c.address)
 
-	c.trx = protocol.NewTransceiver(out)
+	c.trx = protocol.NewTransceiver(out, append([]protocol.TransceiverOption{protocol.WithLogger(c.log())}, c.trxOpts...)...)
 	c.trx.WhenDone(func() {
 		c.StopPolling()
 		out.Close()
 		close(c.closed)
-		log.Printf("disconnected from %s", c.address)
+		c.log().Info("disconnected", "address", c.address)
 	})
 
 	return nil
 }
 
+func (c *Conn) log() *slog.Logger {
+	if c.logger == nil {
+		return slog.Default()
+	}
+	return c.logger
+}
+
 // Close the client connection.
 func (c *Conn) Close() {
 	c.trx.Close()
@@ -116,53 +239,58 @@ func (c *Conn) WhenClosed(f func()) {
 	}()
 }
 
+// Authenticate sends the given password to the server with the password command. rigctld and rigproxy reject all
+// other commands with a security error until the client is authenticated, if they require a password.
+func (c *Conn) Authenticate(ctx context.Context, password string) error {
+	err := c.set(ctx, protocol.LongCommand("password"), password)
+	if err != nil {
+		return err
+	}
+	c.password = password
+	return nil
+}
+
+// WithPriority returns a copy of the given context that lets the requests of a Conn method use the given priority
+// in the send queue. Without explicit priority, set commands are preferred over get commands and poll requests.
+func WithPriority(ctx context.Context, priority protocol.Priority) context.Context {
+	return protocol.WithPriority(ctx, priority)
+}
+
 // Set executes the given hamlib set command with the given parameters.
 func (c *Conn) Set(ctx context.Context, longCommandName string, args ...string) error {
-	request := protocol.Request{Command: protocol.LongCommand(longCommandName), Args: args}
-
-	result := make(chan error)
-	go func() {
-		defer close(result)
-		_, err := c.trx.Send(ctx, request)
-		result <- err
-	}()
+	return c.set(ctx, protocol.LongCommand(longCommandName), args...)
+}
 
-	select {
-	case err := <-result:
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
-	}
+func (c *Conn) set(ctx context.Context, command protocol.Command, args ...string) error {
+	_, err := c.getCommand(ctx, command, args...)
+	return err
 }
 
 func (c *Conn) get(ctx context.Context, longCommandName string, args ...string) (protocol.Response, error) {
-	request := protocol.Request{Command: protocol.LongCommand(longCommandName), Args: args}
+	return c.getCommand(ctx, protocol.LongCommand(longCommandName), args...)
+}
 
-	type resultType struct {
-		response protocol.Response
-		err      error
+func (c *Conn) getCommand(ctx context.Context, command protocol.Command, args ...string) (protocol.Response, error) {
+	request := protocol.Request{Command: command, Args: args, Priority: c.requestPriority(ctx)}
+
+	response, err := c.trx.Send(ctx, request)
+	if err != nil {
+		return protocol.Response{}, err
 	}
-	result := make(chan resultType)
-	go func() {
-		defer close(result)
-		response, err := c.trx.Send(ctx, request)
-		if err != nil {
-			result <- resultType{protocol.Response{}, err}
-			return
-		}
-		if response.Result != "0" {
-			result <- resultType{protocol.Response{}, fmt.Errorf("hamlib: result %s", response.Result)}
-			return
-		}
-		result <- resultType{response, nil}
-	}()
+	err = protocol.ResultError(request.Key(), response.Result)
+	if err != nil {
+		return protocol.Response{}, err
+	}
+	return response, nil
+}
 
-	select {
-	case r := <-result:
-		return r.response, r.err
-	case <-ctx.Done():
-		return protocol.Response{}, ctx.Err()
+// requestPriority returns the priority of a request sent with the given context. The priority of the context takes
+// precedence over the default priority of the Conn.
+func (c *Conn) requestPriority(ctx context.Context) protocol.Priority {
+	if priority := protocol.PriorityFromContext(ctx); priority != protocol.PriorityAuto {
+		return priority
 	}
+	return c.priority
 }
 
 /*
@@ -257,6 +385,34 @@ func (c *Conn) SetVFO(ctx context.Context, vfo VFO) error {
 	return c.Set(ctx, "set_vfo", string(vfo))
 }
 
+/*
+	VFO Operations
+*/
+
+// VFOOp is a VFO operation of the connected radio.
+type VFOOp string
+
+const (
+	VFOOpCopy     VFOOp = "CPY"
+	VFOOpExchange VFOOp = "XCHG"
+	VFOOpFromVFO  VFOOp = "FROM_VFO"
+	VFOOpToVFO    VFOOp = "TO_VFO"
+	VFOOpMemClear VFOOp = "MCL"
+	VFOOpUp       VFOOp = "UP"
+	VFOOpDown     VFOOp = "DOWN"
+	VFOOpBandUp   VFOOp = "BAND_UP"
+	VFOOpBandDown VFOOp = "BAND_DOWN"
+	VFOOpLeft     VFOOp = "LEFT"
+	VFOOpRight    VFOOp = "RIGHT"
+	VFOOpTune     VFOOp = "TUNE"
+	VFOOpToggle   VFOOp = "TOGGLE"
+)
+
+// VFOOperation executes the given VFO operation on the connected radio.
+func (c *Conn) VFOOperation(ctx context.Context, op VFOOp) error {
+	return c.Set(ctx, "vfo_op", string(op))
+}
+
 /*
 	Frequency
 */
@@ -282,7 +438,7 @@ func OnFrequency(callback func(Frequency)) (ResponseHandler, string) {
 		}
 		frequency, err := strconv.ParseFloat(r.Data[0], 64)
 		if err != nil {
-			log.Printf("hamlib: cannot parse frequency result: %v", err)
+			slog.Warn("hamlib: cannot parse frequency result", "error", err)
 			return
 		}
 		callback(Frequency(frequency))
@@ -318,12 +474,12 @@ func (c *Conn) BandSelect(ctx context.Context, band bandplan.BandName) error {
 
 // BandUp switches to the next band upwards on the connected radio and the currently selected VFO.
 func (c *Conn) BandUp(ctx context.Context) error {
-	return c.Set(ctx, "vfo_op", "BAND_UP")
+	return c.VFOOperation(ctx, VFOOpBandUp)
 }
 
 // BandDown switches to the next band downwards on the connected radio and the currently selected VFO.
 func (c *Conn) BandDown(ctx context.Context) error {
-	return c.Set(ctx, "vfo_op", "BAND_DOWN")
+	return c.VFOOperation(ctx, VFOOpBandDown)
 }
 
 // SwitchToBand switches to the given frequency band on the connected radio and the currently selected VFO.
@@ -340,7 +496,7 @@ func (c *Conn) SwitchToBand(ctx context.Context, band bandplan.Band) error {
 	if err == nil {
 		return nil
 	}
-	log.Printf("hamlib: cannot switch to band with BAND_SELECT, using BAND_UP/BAND_DOWN instead: %v", err)
+	c.log().Warn("hamlib: cannot switch to band with BAND_SELECT, using BAND_UP/BAND_DOWN instead", "band", band.Name, "error", err)
 
 	var direction int
 	if currentFrequency > band.FrequencyRange.To {
@@ -407,16 +563,7 @@ const (
 
 // ToBandplanMode maps this Mode value to the type system of the bandplan package.
 func (m Mode) ToBandplanMode() bandplan.Mode {
-	switch m {
-	case ModeCW, ModeCWR:
-		return bandplan.ModeCW
-	case ModeUSB, ModeLSB, ModeAM, ModeFM, ModeWFM, ModeAMS, ModeDSB:
-		return bandplan.ModePhone
-	case ModeRTTY, ModeRTTYR, ModePKTLSB, ModePKTUSB, ModePKTFM, ModeECSSLSB, ModeECSSUSB, ModeFAX, ModeSAM, ModeSAL, ModeSAH:
-		return bandplan.ModeDigital
-	default:
-		return bandplan.ModeDigital
-	}
+	return protocol.BandplanMode(string(m))
 }
 
 // ModeAndPassband returns the current mode and passband (in Hz) setting of the connected radio on the currently selected VFO.
@@ -440,7 +587,7 @@ func OnModeAndPassband(callback func(Mode, Frequency)) (ResponseHandler, string)
 		mode := Mode(r.Data[0])
 		passband, err := strconv.ParseFloat(r.Data[1], 64)
 		if err != nil {
-			log.Printf("hamlib: cannot parse passband result: %v", err)
+			slog.Warn("hamlib: cannot parse passband result", "error", err)
 			return
 		}
 		callback(mode, Frequency(passband))
@@ -452,6 +599,294 @@ func (c *Conn) SetModeAndPassband(ctx context.Context, mode Mode, passband Frequ
 	return c.Set(ctx, "set_mode", string(mode), fmt.Sprintf("%d", int(passband)))
 }
 
+/*
+	Split Operation
+*/
+
+// Split indicates if split operation is enabled on the connected radio and returns the TX VFO.
+func (c *Conn) Split(ctx context.Context) (bool, VFO, error) {
+	response, err := c.get(ctx, "get_split_vfo")
+	if err != nil {
+		return false, "", err
+	}
+	if len(response.Data) < 2 {
+		return false, "", fmt.Errorf("hamlib: incomplete split result: %v", response.Data)
+	}
+	return response.Data[0] == "1", VFO(response.Data[1]), nil
+}
+
+// OnSplit wraps the given callback function into the ResponseHandler interface and translates the generic response to the split state and the TX VFO.
+func OnSplit(callback func(bool, VFO)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) < 2 {
+			return
+		}
+		callback(r.Data[0] == "1", VFO(r.Data[1]))
+	}), "get_split_vfo"
+}
+
+// EnableSplit enables split operation on the connected radio with the given TX VFO.
+func (c *Conn) EnableSplit(ctx context.Context, txVFO VFO) error {
+	return c.Set(ctx, "set_split_vfo", "1", string(txVFO))
+}
+
+// DisableSplit disables split operation on the connected radio.
+func (c *Conn) DisableSplit(ctx context.Context) error {
+	return c.Set(ctx, "set_split_vfo", "0", string(CurrVFO))
+}
+
+// SplitFrequency returns the current TX frequency in Hz of the connected radio.
+func (c *Conn) SplitFrequency(ctx context.Context) (Frequency, error) {
+	response, err := c.get(ctx, "get_split_freq")
+	if err != nil {
+		return 0, err
+	}
+	frequency, err := strconv.ParseFloat(response.Data[0], 64)
+	return Frequency(frequency), err
+}
+
+// OnSplitFrequency wraps the given callback function into the ResponseHandler interface and translates the generic response to the TX frequency.
+func OnSplitFrequency(callback func(Frequency)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		frequency, err := strconv.ParseFloat(r.Data[0], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse split frequency result", "error", err)
+			return
+		}
+		callback(Frequency(frequency))
+	}), "get_split_freq"
+}
+
+// SetSplitFrequency sets the TX frequency in Hz of the connected radio.
+func (c *Conn) SetSplitFrequency(ctx context.Context, frequency Frequency) error {
+	return c.Set(ctx, "set_split_freq", fmt.Sprintf("%d", int(frequency)))
+}
+
+// SplitModeAndPassband returns the current TX mode and passband (in Hz) of the connected radio.
+func (c *Conn) SplitModeAndPassband(ctx context.Context) (Mode, Frequency, error) {
+	response, err := c.get(ctx, "get_split_mode")
+	if err != nil {
+		return ModeNone, 0, err
+	}
+	if len(response.Data) < 2 {
+		return ModeNone, 0, fmt.Errorf("hamlib: incomplete split mode result: %v", response.Data)
+	}
+
+	mode := Mode(response.Data[0])
+	passband, err := strconv.ParseFloat(response.Data[1], 64)
+	return mode, Frequency(passband), err
+}
+
+// OnSplitModeAndPassband wraps the given callback function into the ResponseHandler interface and translates the generic response to TX mode and passband.
+func OnSplitModeAndPassband(callback func(Mode, Frequency)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) < 2 {
+			return
+		}
+		mode := Mode(r.Data[0])
+		passband, err := strconv.ParseFloat(r.Data[1], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse split passband result", "error", err)
+			return
+		}
+		callback(mode, Frequency(passband))
+	}), "get_split_mode"
+}
+
+// SetSplitModeAndPassband sets the TX mode and passband (in Hz) of the connected radio.
+func (c *Conn) SetSplitModeAndPassband(ctx context.Context, mode Mode, passband Frequency) error {
+	return c.Set(ctx, "set_split_mode", string(mode), fmt.Sprintf("%d", int(passband)))
+}
+
+// SplitFrequencyAndMode returns the current TX frequency, mode and passband (in Hz) of the connected radio in one request.
+func (c *Conn) SplitFrequencyAndMode(ctx context.Context) (Frequency, Mode, Frequency, error) {
+	response, err := c.get(ctx, "get_split_freq_mode")
+	if err != nil {
+		return 0, ModeNone, 0, err
+	}
+	if len(response.Data) < 3 {
+		return 0, ModeNone, 0, fmt.Errorf("hamlib: incomplete split frequency and mode result: %v", response.Data)
+	}
+
+	frequency, err := strconv.ParseFloat(response.Data[0], 64)
+	if err != nil {
+		return 0, ModeNone, 0, err
+	}
+	mode := Mode(response.Data[1])
+	passband, err := strconv.ParseFloat(response.Data[2], 64)
+	return Frequency(frequency), mode, Frequency(passband), err
+}
+
+// SetSplitFrequencyAndMode sets the TX frequency, mode and passband (in Hz) of the connected radio in one request.
+func (c *Conn) SetSplitFrequencyAndMode(ctx context.Context, frequency Frequency, mode Mode, passband Frequency) error {
+	return c.Set(ctx, "set_split_freq_mode", fmt.Sprintf("%d", int(frequency)), string(mode), fmt.Sprintf("%d", int(passband)))
+}
+
+// SplitUp enables split operation with the VFO that is not used for RX as TX VFO and sets the TX frequency to the
+// given offset in Hz above the current RX frequency, e.g. SplitUp(ctx, 5000) for "up 5".
+func (c *Conn) SplitUp(ctx context.Context, offset Frequency) error {
+	return c.splitWithOffset(ctx, offset)
+}
+
+// SplitDown enables split operation with the VFO that is not used for RX as TX VFO and sets the TX frequency to the
+// given offset in Hz below the current RX frequency, e.g. SplitDown(ctx, 2000) for "down 2".
+func (c *Conn) SplitDown(ctx context.Context, offset Frequency) error {
+	return c.splitWithOffset(ctx, -offset)
+}
+
+func (c *Conn) splitWithOffset(ctx context.Context, offset Frequency) error {
+	frequency, err := c.Frequency(ctx)
+	if err != nil {
+		return err
+	}
+	rxVFO, err := c.VFO(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.EnableSplit(ctx, otherVFO(rxVFO))
+	if err != nil {
+		return err
+	}
+	return c.SetSplitFrequency(ctx, frequency+offset)
+}
+
+// otherVFO returns the VFO that complements the given RX VFO for split operation.
+func otherVFO(rxVFO VFO) VFO {
+	switch rxVFO {
+	case VFOB:
+		return VFOA
+	case MainVFO:
+		return SubVFO
+	case SubVFO:
+		return MainVFO
+	default:
+		return VFOB
+	}
+}
+
+/*
+	RIT, XIT and IF Shift
+*/
+
+// RIT returns the current RIT offset in Hz of the connected radio on the currently selected VFO.
+func (c *Conn) RIT(ctx context.Context) (Frequency, error) {
+	response, err := c.get(ctx, "get_rit")
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseFloat(response.Data[0], 64)
+	return Frequency(offset), err
+}
+
+// OnRIT wraps the given callback function into the ResponseHandler interface and translates the generic response to the RIT offset.
+func OnRIT(callback func(Frequency)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		offset, err := strconv.ParseFloat(r.Data[0], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse RIT result", "error", err)
+			return
+		}
+		callback(Frequency(offset))
+	}), "get_rit"
+}
+
+// SetRIT sets the RIT offset in Hz of the connected radio on the currently selected VFO.
+func (c *Conn) SetRIT(ctx context.Context, offset Frequency) error {
+	return c.Set(ctx, "set_rit", fmt.Sprintf("%d", int(offset)))
+}
+
+// RITEnabled indicates if the RIT of the connected radio is enabled.
+func (c *Conn) RITEnabled(ctx context.Context) (bool, error) {
+	return c.Func(ctx, FuncRIT)
+}
+
+// EnableRIT enables the RIT of the connected radio.
+func (c *Conn) EnableRIT(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncRIT, true)
+}
+
+// DisableRIT disables the RIT of the connected radio.
+func (c *Conn) DisableRIT(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncRIT, false)
+}
+
+// XIT returns the current XIT offset in Hz of the connected radio on the currently selected VFO.
+func (c *Conn) XIT(ctx context.Context) (Frequency, error) {
+	response, err := c.get(ctx, "get_xit")
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseFloat(response.Data[0], 64)
+	return Frequency(offset), err
+}
+
+// OnXIT wraps the given callback function into the ResponseHandler interface and translates the generic response to the XIT offset.
+func OnXIT(callback func(Frequency)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		offset, err := strconv.ParseFloat(r.Data[0], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse XIT result", "error", err)
+			return
+		}
+		callback(Frequency(offset))
+	}), "get_xit"
+}
+
+// SetXIT sets the XIT offset in Hz of the connected radio on the currently selected VFO.
+func (c *Conn) SetXIT(ctx context.Context, offset Frequency) error {
+	return c.Set(ctx, "set_xit", fmt.Sprintf("%d", int(offset)))
+}
+
+// XITEnabled indicates if the XIT of the connected radio is enabled.
+func (c *Conn) XITEnabled(ctx context.Context) (bool, error) {
+	return c.Func(ctx, FuncXIT)
+}
+
+// EnableXIT enables the XIT of the connected radio.
+func (c *Conn) EnableXIT(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncXIT, true)
+}
+
+// DisableXIT disables the XIT of the connected radio.
+func (c *Conn) DisableXIT(ctx context.Context) error {
+	return c.SetFunc(ctx, FuncXIT, false)
+}
+
+// IFShift returns the current IF shift in Hz of the connected radio.
+func (c *Conn) IFShift(ctx context.Context) (Frequency, error) {
+	shift, err := c.Level(ctx, LevelIF)
+	return Frequency(shift), err
+}
+
+// OnIFShift wraps the given callback function into the ResponseHandler interface and translates the generic response to the IF shift.
+func OnIFShift(callback func(Frequency)) (ResponseHandler, string, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		shift, err := strconv.ParseFloat(r.Data[0], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse IF shift result", "error", err)
+			return
+		}
+		callback(Frequency(shift))
+	}), "get_level", "IF"
+}
+
+// SetIFShift sets the IF shift in Hz of the connected radio.
+func (c *Conn) SetIFShift(ctx context.Context, shift Frequency) error {
+	return c.SetLevel(ctx, LevelIF, float64(shift))
+}
+
 /*
 	Power Level
 */
@@ -474,7 +909,7 @@ func OnPowerLevel(callback func(float64)) (ResponseHandler, string, string) {
 		}
 		powerLevel, err := strconv.ParseFloat(r.Data[0], 64)
 		if err != nil {
-			log.Printf("hamlib: cannot parse power level result: %v", err)
+			slog.Warn("hamlib: cannot parse power level result", "error", err)
 			return
 		}
 		callback(powerLevel)
@@ -556,7 +991,7 @@ func OnMorseSpeed(callback func(int)) (ResponseHandler, string, string) {
 		}
 		wpm, err := strconv.Atoi(r.Data[0])
 		if err != nil {
-			log.Printf("hamlib: cannot parse morse speed result: %v", err)
+			slog.Warn("hamlib: cannot parse morse speed result", "error", err)
 			return
 		}
 		callback(wpm)
@@ -567,3 +1002,79 @@ func OnMorseSpeed(callback func(int)) (ResponseHandler, string, string) {
 func (c *Conn) SetMorseSpeed(ctx context.Context, wpm int) error {
 	return c.Set(ctx, "set_level", "KEYSPD", fmt.Sprintf("%d", wpm))
 }
+
+// WaitMorse waits until the connected radio has sent all queued morse code.
+func (c *Conn) WaitMorse(ctx context.Context) error {
+	return c.Set(ctx, "wait_morse")
+}
+
+/*
+	DTMF and Voice Memory
+*/
+
+const dtmfDigits = "0123456789ABCD*#"
+
+// SendDTMF sends the given DTMF digits through the connected radio. Valid digits are 0-9, A-D, * and #.
+func (c *Conn) SendDTMF(ctx context.Context, digits string) error {
+	digits = strings.ToUpper(digits)
+	if digits == "" {
+		return fmt.Errorf("%w: no DTMF digits", protocol.ErrArgumentOutOfDomain)
+	}
+	for _, digit := range digits {
+		if !strings.ContainsRune(dtmfDigits, digit) {
+			return fmt.Errorf("%w: invalid DTMF digit %q", protocol.ErrArgumentOutOfDomain, digit)
+		}
+	}
+	return c.Set(ctx, "send_dtmf", digits)
+}
+
+// ReceiveDTMF returns the DTMF digits that were received by the connected radio.
+func (c *Conn) ReceiveDTMF(ctx context.Context) (string, error) {
+	response, err := c.get(ctx, "recv_dtmf")
+	if err != nil {
+		return "", err
+	}
+	if len(response.Data) == 0 {
+		return "", nil
+	}
+	return response.Data[0], nil
+}
+
+// SendVoiceMemory plays the given voice memory of the connected radio.
+func (c *Conn) SendVoiceMemory(ctx context.Context, channel int) error {
+	if channel < 1 {
+		return fmt.Errorf("%w: invalid voice memory %d", protocol.ErrArgumentOutOfDomain, channel)
+	}
+	return c.Set(ctx, "send_voice_mem", strconv.Itoa(channel))
+}
+
+/*
+	Raw CAT
+*/
+
+// SendRawCAT sends the given raw CAT command to the connected radio and returns the raw reply. If the terminator is
+// not 0, rigctld reads the reply up to the given terminator, otherwise it uses the default terminator of the radio.
+// The terminator is sent as raw character, since rigctld does not decode escapes in the terminator argument.
+// Raw commands bypass the cache of rigproxy and flush it, since they may change any state of the radio.
+func (c *Conn) SendRawCAT(ctx context.Context, command []byte, terminator byte) ([]byte, error) {
+	var response protocol.Response
+	var err error
+	if terminator == 0 {
+		response, err = c.get(ctx, "send_cmd", protocol.EncodeCAT(command))
+	} else {
+		response, err = c.get(ctx, "send_cmd_rx", protocol.EncodeCAT(command), string([]byte{terminator}))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	for _, line := range response.Data {
+		reply, err := protocol.DecodeCAT(line)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, reply...)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+type recordingMetrics []protocol.CommandKey
+
+func (m *recordingMetrics) ObserveTransmission(command protocol.CommandKey, _ time.Duration, _ error) {
+	*m = append(*m, command)
+}
+
+func TestOpenWithOptions(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		line, _ := r.ReadString('\n')
+		if line == "+\\get_freq\n" {
+			fmt.Fprint(conn, "get_freq:\nFrequency: 14074000\nRPRT 0\n")
+		}
+		// leave the second request unanswered
+		r.ReadString('\n')
+		time.Sleep(time.Second)
+	}()
+
+	metrics := new(recordingMetrics)
+	conn, err := Open(l.Addr().String(),
+		WithDialer(&net.Dialer{Timeout: time.Second}),
+		WithRequestTimeout(50*time.Millisecond),
+		WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	frequency, err := conn.Frequency(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Frequency(14074000), frequency)
+
+	_, _, err = conn.ModeAndPassband(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Equal(t, recordingMetrics{"get_freq", "get_mode"}, *metrics)
+}
+
+func TestOtherVFO(t *testing.T) {
+	testCases := []struct {
+		rx       VFO
+		expected VFO
+	}{
+		{VFOA, VFOB},
+		{VFOB, VFOA},
+		{MainVFO, SubVFO},
+		{SubVFO, MainVFO},
+		{MEMVFO, VFOB},
+	}
+	for _, tC := range testCases {
+		t.Run(string(tC.rx), func(t *testing.T) {
+			assert.Equal(t, tC.expected, otherVFO(tC.rx))
+		})
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	conn := &Conn{}
+	assert.Equal(t, protocol.PriorityAuto, conn.requestPriority(context.Background()))
+	assert.Equal(t, protocol.PriorityGet, conn.requestPriority(WithPriority(context.Background(), protocol.PriorityGet)))
+
+	WithDefaultPriority(protocol.PriorityPoll)(conn)
+	assert.Equal(t, protocol.PriorityPoll, conn.requestPriority(context.Background()))
+	assert.Equal(t, protocol.PrioritySet, conn.requestPriority(WithPriority(context.Background(), protocol.PrioritySet)))
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+/*
+	Rig Information
+*/
+
+// VFOState describes the state of a VFO of the connected radio.
+type VFOState struct {
+	VFO       VFO
+	Frequency Frequency
+	Mode      Mode
+	Passband  Frequency
+	RX        bool
+	TX        bool
+}
+
+// RigInfo describes the state of the connected radio.
+type RigInfo struct {
+	VFOs    []VFOState
+	Split   bool
+	SatMode bool
+	// PTT is only reported by newer Hamlib versions, HasPTT indicates if the PTT state is valid.
+	PTT     bool
+	HasPTT  bool
+	Rig     string
+	Model   int
+	Version string
+}
+
+// RigInfo returns the state of all VFOs, the split state and, if reported by rigctld, the PTT state of the connected
+// radio in one round trip.
+func (c *Conn) RigInfo(ctx context.Context) (RigInfo, error) {
+	response, err := c.get(ctx, "get_rig_info")
+	if err != nil {
+		return RigInfo{}, err
+	}
+	return parseRigInfo(response)
+}
+
+// OnRigInfo wraps the given callback function into the ResponseHandler interface and translates the generic response to a RigInfo value.
+func OnRigInfo(callback func(RigInfo)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		info, err := parseRigInfo(r)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse rig info result", "error", err)
+			return
+		}
+		callback(info)
+	}), "get_rig_info"
+}
+
+func parseRigInfo(r protocol.Response) (RigInfo, error) {
+	info, err := protocol.ParseRigInfo(r)
+	if err != nil {
+		return RigInfo{}, err
+	}
+	result := RigInfo{
+		VFOs:    make([]VFOState, 0, len(info.VFOs)),
+		Split:   info.Split,
+		SatMode: info.SatMode,
+		PTT:     info.PTT,
+		HasPTT:  info.HasPTT,
+		Rig:     info.Rig,
+		Model:   info.Model,
+		Version: info.Version,
+	}
+	for _, vfo := range info.VFOs {
+		result.VFOs = append(result.VFOs, VFOState{
+			VFO:       VFO(vfo.Name),
+			Frequency: Frequency(vfo.Frequency),
+			Mode:      Mode(vfo.Mode),
+			Passband:  Frequency(vfo.Width),
+			RX:        vfo.RX,
+			TX:        vfo.TX,
+		})
+	}
+	return result, nil
+}
+
+// VFOInfo describes the state of a single VFO of the connected radio.
+type VFOInfo struct {
+	Frequency Frequency
+	Mode      Mode
+	Passband  Frequency
+	Split     bool
+	SatMode   bool
+}
+
+// VFOInfo returns the state of the given VFO of the connected radio.
+func (c *Conn) VFOInfo(ctx context.Context, vfo VFO) (VFOInfo, error) {
+	response, err := c.get(ctx, "get_vfo_info", string(vfo))
+	if err != nil {
+		return VFOInfo{}, err
+	}
+	info, err := protocol.ParseVFOInfo(response)
+	if err != nil {
+		return VFOInfo{}, err
+	}
+	return VFOInfo{
+		Frequency: Frequency(info.Frequency),
+		Mode:      Mode(info.Mode),
+		Passband:  Frequency(info.Width),
+		Split:     info.Split,
+		SatMode:   info.SatMode,
+	}, nil
+}
+
+// VFOList returns the VFOs that are available on the connected radio.
+func (c *Conn) VFOList(ctx context.Context) ([]VFO, error) {
+	response, err := c.get(ctx, "get_vfo_list")
+	if err != nil {
+		return nil, err
+	}
+	names := protocol.ParseVFOList(response)
+	result := make([]VFO, len(names))
+	for i, name := range names {
+		result[i] = VFO(name)
+	}
+	return result, nil
+}
+
+// Modes returns the modes that are supported by the connected radio.
+func (c *Conn) Modes(ctx context.Context) ([]Mode, error) {
+	response, err := c.get(ctx, "get_modes")
+	if err != nil {
+		return nil, err
+	}
+	names := protocol.ParseModes(response)
+	result := make([]Mode, len(names))
+	for i, name := range names {
+		result[i] = Mode(name)
+	}
+	return result, nil
+}
+
+// Bandwidths contains the normal, narrow and wide passband of a mode.
+type Bandwidths struct {
+	Normal Frequency
+	Narrow Frequency
+	Wide   Frequency
+}
+
+// ModeBandwidths returns the passbands of the given mode on the connected radio.
+func (c *Conn) ModeBandwidths(ctx context.Context, mode Mode) (Bandwidths, error) {
+	response, err := c.get(ctx, "get_mode_bandwidths", string(mode))
+	if err != nil {
+		return Bandwidths{}, err
+	}
+	bandwidths, err := protocol.ParseModeBandwidths(response)
+	if err != nil {
+		return Bandwidths{}, err
+	}
+	return Bandwidths{
+		Normal: Frequency(bandwidths.Normal),
+		Narrow: Frequency(bandwidths.Narrow),
+		Wide:   Frequency(bandwidths.Wide),
+	}, nil
+}
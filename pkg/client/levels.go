@@ -0,0 +1,387 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// ValueType describes the domain of the value of a level or a parameter.
+type ValueType int
+
+const (
+	// ValueInt is an integer value.
+	ValueInt ValueType = iota
+	// ValueRatio is a floating point value between 0 and 1.
+	ValueRatio
+	// ValueFloat is a floating point value without a fixed domain, e.g. a SWR or a voltage.
+	ValueFloat
+	// ValueDB is an integer value in dB.
+	ValueDB
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case ValueInt:
+		return "int"
+	case ValueRatio:
+		return "float 0..1"
+	case ValueFloat:
+		return "float"
+	case ValueDB:
+		return "dB"
+	default:
+		return "unknown"
+	}
+}
+
+// Parse the given string value according to this value type.
+func (t ValueType) Parse(s string) (float64, error) {
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if t == ValueInt || t == ValueDB {
+		return math.Round(value), nil
+	}
+	return value, nil
+}
+
+// Format the given value according to this value type.
+func (t ValueType) Format(value float64) string {
+	switch t {
+	case ValueInt, ValueDB:
+		return strconv.Itoa(int(math.Round(value)))
+	default:
+		return fmt.Sprintf("%f", value)
+	}
+}
+
+// Validate that the given value is within the domain of this value type.
+func (t ValueType) Validate(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%w: %v is not a valid %s value", protocol.ErrArgumentOutOfDomain, value, t)
+	}
+	if t == ValueRatio && (value < 0 || value > 1) {
+		return fmt.Errorf("%w: %v is not within 0..1", protocol.ErrArgumentOutOfDomain, value)
+	}
+	return nil
+}
+
+/*
+	Levels
+*/
+
+// Level is the name of a Hamlib level.
+type Level string
+
+const (
+	LevelPreamp            Level = "PREAMP"
+	LevelAttenuator        Level = "ATT"
+	LevelVOXDelay          Level = "VOXDELAY"
+	LevelAF                Level = "AF"
+	LevelRF                Level = "RF"
+	LevelSquelch           Level = "SQL"
+	LevelIF                Level = "IF"
+	LevelAPF               Level = "APF"
+	LevelNR                Level = "NR"
+	LevelPBTIn             Level = "PBT_IN"
+	LevelPBTOut            Level = "PBT_OUT"
+	LevelCWPitch           Level = "CWPITCH"
+	LevelRFPower           Level = "RFPOWER"
+	LevelMicGain           Level = "MICGAIN"
+	LevelKeySpeed          Level = "KEYSPD"
+	LevelNotchFrequency    Level = "NOTCHF"
+	LevelCompression       Level = "COMP"
+	LevelAGC               Level = "AGC"
+	LevelBreakInDelay      Level = "BKINDL"
+	LevelBalance           Level = "BAL"
+	LevelMeter             Level = "METER"
+	LevelVOXGain           Level = "VOXGAIN"
+	LevelAntiVOX           Level = "ANTIVOX"
+	LevelSlopeLow          Level = "SLOPE_LOW"
+	LevelSlopeHigh         Level = "SLOPE_HIGH"
+	LevelBreakInDelayMS    Level = "BKIN_DLYMS"
+	LevelRawStrength       Level = "RAWSTR"
+	LevelSWR               Level = "SWR"
+	LevelALC               Level = "ALC"
+	LevelStrength          Level = "STRENGTH"
+	LevelRFPowerMeter      Level = "RFPOWER_METER"
+	LevelRFPowerMeterWatts Level = "RFPOWER_METER_WATTS"
+	LevelCompMeter         Level = "COMP_METER"
+	LevelVDMeter           Level = "VD_METER"
+	LevelIDMeter           Level = "ID_METER"
+	LevelNotchFrequencyRaw Level = "NOTCHF_RAW"
+	LevelMonitorGain       Level = "MONITOR_GAIN"
+	LevelNB                Level = "NB"
+	LevelAGCTime           Level = "AGC_TIME"
+	LevelBandSelect        Level = "BAND_SELECT"
+	LevelUSBAF             Level = "USB_AF"
+	LevelTempMeter         Level = "TEMP_METER"
+)
+
+var levelTypes = map[Level]ValueType{
+	LevelPreamp:            ValueDB,
+	LevelAttenuator:        ValueDB,
+	LevelVOXDelay:          ValueInt,
+	LevelAF:                ValueRatio,
+	LevelRF:                ValueRatio,
+	LevelSquelch:           ValueRatio,
+	LevelIF:                ValueInt,
+	LevelAPF:               ValueRatio,
+	LevelNR:                ValueRatio,
+	LevelPBTIn:             ValueFloat,
+	LevelPBTOut:            ValueFloat,
+	LevelCWPitch:           ValueInt,
+	LevelRFPower:           ValueRatio,
+	LevelMicGain:           ValueRatio,
+	LevelKeySpeed:          ValueInt,
+	LevelNotchFrequency:    ValueInt,
+	LevelCompression:       ValueRatio,
+	LevelAGC:               ValueInt,
+	LevelBreakInDelay:      ValueInt,
+	LevelBalance:           ValueRatio,
+	LevelMeter:             ValueInt,
+	LevelVOXGain:           ValueRatio,
+	LevelAntiVOX:           ValueRatio,
+	LevelSlopeLow:          ValueInt,
+	LevelSlopeHigh:         ValueInt,
+	LevelBreakInDelayMS:    ValueInt,
+	LevelRawStrength:       ValueInt,
+	LevelSWR:               ValueFloat,
+	LevelALC:               ValueRatio,
+	LevelStrength:          ValueDB,
+	LevelRFPowerMeter:      ValueRatio,
+	LevelRFPowerMeterWatts: ValueFloat,
+	LevelCompMeter:         ValueFloat,
+	LevelVDMeter:           ValueFloat,
+	LevelIDMeter:           ValueFloat,
+	LevelNotchFrequencyRaw: ValueRatio,
+	LevelMonitorGain:       ValueRatio,
+	LevelNB:                ValueRatio,
+	LevelAGCTime:           ValueFloat,
+	LevelBandSelect:        ValueInt,
+	LevelUSBAF:             ValueRatio,
+	LevelTempMeter:         ValueFloat,
+}
+
+// Type returns the value type of this level. Unknown levels are treated as ValueFloat.
+func (l Level) Type() ValueType {
+	t, ok := levelTypes[l]
+	if !ok {
+		return ValueFloat
+	}
+	return t
+}
+
+// AGC values of LevelAGC.
+const (
+	AGCOff       = 0
+	AGCSuperFast = 1
+	AGCFast      = 2
+	AGCSlow      = 3
+	AGCUser      = 4
+	AGCMedium    = 5
+	AGCAuto      = 6
+)
+
+// Level returns the current value of the given level of the connected radio.
+func (c *Conn) Level(ctx context.Context, level Level) (float64, error) {
+	response, err := c.get(ctx, "get_level", string(level))
+	if err != nil {
+		return 0, err
+	}
+	return level.Type().Parse(response.Data[0])
+}
+
+// OnLevel wraps the given callback function into the ResponseHandler interface and translates the generic response to the value of the given level.
+func OnLevel(level Level, callback func(float64)) (ResponseHandler, string, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		value, err := level.Type().Parse(r.Data[0])
+		if err != nil {
+			slog.Warn("hamlib: cannot parse level result", "level", level, "error", err)
+			return
+		}
+		callback(value)
+	}), "get_level", string(level)
+}
+
+// SetLevel sets the given level of the connected radio to the given value. The value must be within the domain of the level's value type.
+func (c *Conn) SetLevel(ctx context.Context, level Level, value float64) error {
+	t := level.Type()
+	err := t.Validate(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for level %s: %w", level, err)
+	}
+	return c.Set(ctx, "set_level", string(level), t.Format(value))
+}
+
+/*
+	Functions
+*/
+
+// Function is the name of a Hamlib function that can be switched on or off.
+type Function string
+
+const (
+	FuncFastAGC             Function = "FAGC"
+	FuncNB                  Function = "NB"
+	FuncCompression         Function = "COMP"
+	FuncVOX                 Function = "VOX"
+	FuncTone                Function = "TONE"
+	FuncToneSquelch         Function = "TSQL"
+	FuncSemiBreakIn         Function = "SBKIN"
+	FuncFullBreakIn         Function = "FBKIN"
+	FuncANF                 Function = "ANF"
+	FuncNR                  Function = "NR"
+	FuncAIP                 Function = "AIP"
+	FuncAPF                 Function = "APF"
+	FuncMonitor             Function = "MON"
+	FuncManualNotch         Function = "MN"
+	FuncRF                  Function = "RF"
+	FuncAutoRepeater        Function = "ARO"
+	FuncLock                Function = "LOCK"
+	FuncMute                Function = "MUTE"
+	FuncVSC                 Function = "VSC"
+	FuncReverse             Function = "REV"
+	FuncSquelch             Function = "SQL"
+	FuncABM                 Function = "ABM"
+	FuncBeatCanceller       Function = "BC"
+	FuncManualBeatCanceller Function = "MBC"
+	FuncRIT                 Function = "RIT"
+	FuncAFC                 Function = "AFC"
+	FuncSatelliteMode       Function = "SATMODE"
+	FuncScope               Function = "SCOPE"
+	FuncResume              Function = "RESUME"
+	FuncToneBurst           Function = "TBURST"
+	FuncTuner               Function = "TUNER"
+	FuncXIT                 Function = "XIT"
+	FuncNB2                 Function = "NB2"
+	FuncCSQL                Function = "CSQL"
+	FuncAFLT                Function = "AFLT"
+	FuncANL                 Function = "ANL"
+	FuncBeatCanceller2      Function = "BC2"
+	FuncDualWatch           Function = "DUAL_WATCH"
+	FuncDiversity           Function = "DIVERSITY"
+	FuncDigitalSquelch      Function = "DSQL"
+	FuncScrambler           Function = "SCEN"
+	FuncSlice               Function = "SLICE"
+	FuncTransceive          Function = "TRANSCEIVE"
+	FuncSpectrum            Function = "SPECTRUM"
+	FuncSpectrumHold        Function = "SPECTRUM_HOLD"
+	FuncSendMorse           Function = "SEND_MORSE"
+	FuncSendVoiceMemory     Function = "SEND_VOICE_MEM"
+	FuncOverflowStatus      Function = "OVF_STATUS"
+	FuncSync                Function = "SYNC"
+)
+
+// Func indicates if the given function of the connected radio is switched on.
+func (c *Conn) Func(ctx context.Context, function Function) (bool, error) {
+	response, err := c.get(ctx, "get_func", string(function))
+	if err != nil {
+		return false, err
+	}
+	return response.Data[0] == "1", nil
+}
+
+// OnFunc wraps the given callback function into the ResponseHandler interface and translates the generic response to the state of the given function.
+func OnFunc(function Function, callback func(bool)) (ResponseHandler, string, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		callback(r.Data[0] == "1")
+	}), "get_func", string(function)
+}
+
+// SetFunc switches the given function of the connected radio on or off.
+func (c *Conn) SetFunc(ctx context.Context, function Function, on bool) error {
+	status := "0"
+	if on {
+		status = "1"
+	}
+	return c.Set(ctx, "set_func", string(function), status)
+}
+
+/*
+	Parameters
+*/
+
+// Parm is the name of a Hamlib parameter.
+type Parm string
+
+const (
+	ParmAnnounce     Parm = "ANN"
+	ParmAutoPowerOff Parm = "APO"
+	ParmBacklight    Parm = "BACKLIGHT"
+	ParmBeep         Parm = "BEEP"
+	ParmTime         Parm = "TIME"
+	ParmBattery      Parm = "BAT"
+	ParmKeyLight     Parm = "KEYLIGHT"
+	ParmScreenSaver  Parm = "SCREENSAVER"
+	ParmAFIF         Parm = "AFIF"
+	ParmKeyerType    Parm = "KEYERTYPE"
+)
+
+var parmTypes = map[Parm]ValueType{
+	ParmAnnounce:     ValueInt,
+	ParmAutoPowerOff: ValueInt,
+	ParmBacklight:    ValueRatio,
+	ParmBeep:         ValueInt,
+	ParmTime:         ValueInt,
+	ParmBattery:      ValueRatio,
+	ParmKeyLight:     ValueRatio,
+	ParmScreenSaver:  ValueInt,
+	ParmAFIF:         ValueInt,
+	ParmKeyerType:    ValueInt,
+}
+
+// Type returns the value type of this parameter. Unknown parameters are treated as ValueFloat.
+func (p Parm) Type() ValueType {
+	t, ok := parmTypes[p]
+	if !ok {
+		return ValueFloat
+	}
+	return t
+}
+
+// Parm returns the current value of the given parameter of the connected radio.
+func (c *Conn) Parm(ctx context.Context, parm Parm) (float64, error) {
+	response, err := c.get(ctx, "get_parm", string(parm))
+	if err != nil {
+		return 0, err
+	}
+	return parm.Type().Parse(response.Data[0])
+}
+
+// OnParm wraps the given callback function into the ResponseHandler interface and translates the generic response to the value of the given parameter.
+func OnParm(parm Parm, callback func(float64)) (ResponseHandler, string, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		value, err := parm.Type().Parse(r.Data[0])
+		if err != nil {
+			slog.Warn("hamlib: cannot parse parm result", "parm", parm, "error", err)
+			return
+		}
+		callback(value)
+	}), "get_parm", string(parm)
+}
+
+// SetParm sets the given parameter of the connected radio to the given value. The value must be within the domain of the parameter's value type.
+func (c *Conn) SetParm(ctx context.Context, parm Parm, value float64) error {
+	t := parm.Type()
+	err := t.Validate(value)
+	if err != nil {
+		return fmt.Errorf("invalid value for parm %s: %w", parm, err)
+	}
+	return c.Set(ctx, "set_parm", string(parm), t.Format(value))
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestValueTypes(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		level     Level
+		value     string
+		expected  float64
+		formatted string
+	}{
+		{"ratio", LevelAF, "0.500000", 0.5, "0.500000"},
+		{"int", LevelKeySpeed, "24", 24, "24"},
+		{"dB", LevelStrength, "-12", -12, "-12"},
+		{"float", LevelSWR, "1.5", 1.5, "1.500000"},
+		{"unknown level", Level("UNKNOWN"), "3.25", 3.25, "3.250000"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := tC.level.Type().Parse(tC.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expected, actual)
+			assert.Equal(t, tC.formatted, tC.level.Type().Format(actual))
+		})
+	}
+}
+
+func TestValidateRatio(t *testing.T) {
+	assert.NoError(t, ValueRatio.Validate(0))
+	assert.NoError(t, ValueRatio.Validate(1))
+
+	err := ValueRatio.Validate(1.5)
+	assert.True(t, errors.Is(err, protocol.ErrArgumentOutOfDomain))
+	assert.NoError(t, ValueDB.Validate(-20))
+}
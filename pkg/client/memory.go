@@ -0,0 +1,608 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// Channel represents a memory channel of the connected radio.
+type Channel struct {
+	Number         int
+	Name           string
+	Frequency      Frequency
+	Mode           Mode
+	Width          Frequency
+	Split          bool
+	TXFrequency    Frequency
+	TXMode         Mode
+	TXWidth        Frequency
+	RepeaterShift  RepeaterShift
+	RepeaterOffset Frequency
+	TuningStep     Frequency
+	CTCSSTone      CTCSSTone
+	CTCSSSquelch   CTCSSTone
+	DCSCode        DCSCode
+	DCSSquelch     DCSCode
+}
+
+// Empty indicates if this channel has no frequency stored.
+func (ch Channel) Empty() bool {
+	return ch.Frequency == 0
+}
+
+/*
+	Memory Selection
+*/
+
+// MemoryChannel returns the number of the currently selected memory channel of the connected radio.
+func (c *Conn) MemoryChannel(ctx context.Context) (int, error) {
+	response, err := c.get(ctx, "get_mem")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(response.Data[0])
+}
+
+// SetMemoryChannel selects the given memory channel on the connected radio.
+func (c *Conn) SetMemoryChannel(ctx context.Context, number int) error {
+	return c.Set(ctx, "set_mem", strconv.Itoa(number))
+}
+
+// SetMemoryBank selects the given memory bank on the connected radio.
+func (c *Conn) SetMemoryBank(ctx context.Context, bank int) error {
+	return c.Set(ctx, "set_bank", strconv.Itoa(bank))
+}
+
+/*
+	Channel Read and Write
+*/
+
+// Channel reads the content of the given memory channel from the connected radio.
+func (c *Conn) Channel(ctx context.Context, number int) (Channel, error) {
+	response, err := c.get(ctx, "get_channel", strconv.Itoa(number), "1")
+	if err != nil {
+		return Channel{}, err
+	}
+	return parseChannel(response.Lines())
+}
+
+// SetChannel writes the given channel into the memory of the connected radio. Only the fields that are supported by
+// the radio's memory are written, as reported by the memory capabilities of dump_caps.
+func (c *Conn) SetChannel(ctx context.Context, channel Channel) error {
+	caps, err := c.MemoryCaps(ctx)
+	if err != nil {
+		return err
+	}
+	return c.setChannel(ctx, caps, channel)
+}
+
+func (c *Conn) setChannel(ctx context.Context, caps []MemoryCaps, channel Channel) error {
+	fields, ok := memoryCapsFor(caps, channel.Number)
+	if !ok {
+		return fmt.Errorf("%w: no memory channel %d", protocol.ErrInvalidParameter, channel.Number)
+	}
+	return c.Set(ctx, "set_channel", formatChannel(channel, fields))
+}
+
+// BackupChannels reads all non-empty memory channels from the connected radio.
+func (c *Conn) BackupChannels(ctx context.Context) ([]Channel, error) {
+	caps, err := c.MemoryCaps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result []Channel
+	for _, r := range caps {
+		channels, err := c.BackupChannelRange(ctx, r.From, r.To)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, channels...)
+	}
+	return result, nil
+}
+
+// BackupChannelRange reads all non-empty memory channels within the given range of channel numbers from the connected radio.
+func (c *Conn) BackupChannelRange(ctx context.Context, from, to int) ([]Channel, error) {
+	result := make([]Channel, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		channel, err := c.Channel(ctx, number)
+		if errors.Is(err, protocol.ErrInvalidParameter) || errors.Is(err, protocol.ErrArgumentOutOfDomain) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read memory channel %d: %w", number, err)
+		}
+		if channel.Empty() {
+			continue
+		}
+		result = append(result, channel)
+	}
+	return result, nil
+}
+
+// RestoreChannels writes all given channels into the memory of the connected radio.
+func (c *Conn) RestoreChannels(ctx context.Context, channels []Channel) error {
+	caps, err := c.MemoryCaps(ctx)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		err := c.setChannel(ctx, caps, channel)
+		if err != nil {
+			return fmt.Errorf("cannot write memory channel %d: %w", channel.Number, err)
+		}
+	}
+	return nil
+}
+
+/*
+	Memory Capabilities
+*/
+
+// MemoryCaps describes which fields are stored in a range of memory channels.
+type MemoryCaps struct {
+	From   int
+	To     int
+	Fields []string
+}
+
+var memoryRangeExpression = regexp.MustCompile(`^\s*(\d+)\.\.(\d+):`)
+
+// MemoryCaps returns the memory capabilities of the connected radio.
+func (c *Conn) MemoryCaps(ctx context.Context) ([]MemoryCaps, error) {
+	response, err := c.get(ctx, "dump_caps")
+	if err != nil {
+		return nil, err
+	}
+	return parseMemoryCaps(response.Lines()), nil
+}
+
+func parseMemoryCaps(lines []string) []MemoryCaps {
+	var result []MemoryCaps
+	var current *MemoryCaps
+	for _, line := range lines {
+		if match := memoryRangeExpression.FindStringSubmatch(line); match != nil {
+			from, _ := strconv.Atoi(match[1])
+			to, _ := strconv.Atoi(match[2])
+			result = append(result, MemoryCaps{From: from, To: to})
+			current = &result[len(result)-1]
+			continue
+		}
+		_, fields, ok := strings.Cut(line, "Mem caps:")
+		if ok && current != nil {
+			current.Fields = strings.Fields(fields)
+		}
+	}
+	return result
+}
+
+func memoryCapsFor(caps []MemoryCaps, number int) ([]string, bool) {
+	for _, c := range caps {
+		if c.From <= number && number <= c.To {
+			return c.Fields, true
+		}
+	}
+	return nil, false
+}
+
+// formatChannel formats the arguments of the set_channel command in the order that is expected by rigctld.
+func formatChannel(channel Channel, fields []string) string {
+	has := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		has[field] = true
+	}
+
+	args := []string{strconv.Itoa(channel.Number)}
+	add := func(field string, value string) {
+		if has[field] {
+			args = append(args, value)
+		}
+	}
+	add("BANK", "0")
+	add("ANT", "0")
+	add("FREQ", strconv.Itoa(int(channel.Frequency)))
+	add("MODE", string(channel.Mode))
+	add("WIDTH", strconv.Itoa(int(channel.Width)))
+	add("TXFREQ", strconv.Itoa(int(channel.TXFrequency)))
+	add("TXMODE", string(channel.TXMode))
+	add("TXWIDTH", strconv.Itoa(int(channel.TXWidth)))
+	add("SPLIT", boolArg(channel.Split))
+	add("RPTRSHIFT", repeaterShiftArg(channel.RepeaterShift))
+	add("RPTROFS", strconv.Itoa(int(channel.RepeaterOffset)))
+	add("TS", strconv.Itoa(int(channel.TuningStep)))
+	add("RIT", "0")
+	add("XIT", "0")
+	add("FUNC", "0")
+	add("TONE", channel.CTCSSTone.hamlib())
+	add("CTCSS", channel.CTCSSSquelch.hamlib())
+	add("DCSCODE", channel.DCSCode.hamlib())
+	add("DCSSQL", channel.DCSSquelch.hamlib())
+	add("SCANGRP", "0")
+	add("FLAG", "0")
+	add("NAME", channelNameArg(channel.Name))
+
+	return strings.Join(args, " ")
+}
+
+func boolArg(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func repeaterShiftArg(shift RepeaterShift) string {
+	if shift == RepeaterShiftPlus || shift == RepeaterShiftMinus {
+		return string(shift)
+	}
+	return "0"
+}
+
+// channelNameArg replaces all whitespace in the name, since rigctld reads the name as a single word.
+func channelNameArg(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return strings.Join(strings.Fields(name), "_")
+}
+
+/*
+	Channel Parsing
+*/
+
+var (
+	channelNameExpression  = regexp.MustCompile(`Name: '(.*)'`)
+	channelFieldExpression = regexp.MustCompile(`([A-Za-z]+):\s+([^,\t]*)`)
+)
+
+// parseChannel parses the channel dump of rigctld's get_channel command.
+func parseChannel(lines []string) (Channel, error) {
+	var result Channel
+	var err error
+	found := false
+	for _, line := range lines {
+		if match := channelNameExpression.FindStringSubmatch(line); match != nil {
+			result.Name = match[1]
+			line = channelNameExpression.ReplaceAllString(line, "")
+		}
+		for _, match := range channelFieldExpression.FindAllStringSubmatch(line, -1) {
+			value := strings.TrimSpace(match[2])
+			switch match[1] {
+			case "Channel":
+				found = true
+				result.Number, err = strconv.Atoi(value)
+			case "Split":
+				result.Split = value == "ON"
+			case "Freq":
+				result.Frequency, err = parseFrequencyWithUnit(value)
+			case "Mode":
+				result.Mode = Mode(value)
+			case "Width":
+				result.Width, err = parseFrequencyWithUnit(value)
+			case "txFreq":
+				result.TXFrequency, err = parseFrequencyWithUnit(value)
+			case "txMode":
+				result.TXMode = Mode(value)
+			case "txWidth":
+				result.TXWidth, err = parseFrequencyWithUnit(value)
+			case "Shift":
+				result.RepeaterShift = RepeaterShift(value)
+			case "Offset":
+				result.RepeaterOffset, err = parseFrequencyWithUnit(strings.TrimLeft(value, "+-"))
+			case "Step":
+				result.TuningStep, err = parseFrequencyWithUnit(value)
+			case "CTCSS":
+				result.CTCSSTone, err = parseDumpedTone(value)
+			case "CTCSSsql":
+				result.CTCSSSquelch, err = parseDumpedTone(value)
+			case "DCS":
+				result.DCSCode, err = parseDumpedCode(value)
+			case "DCSsql":
+				result.DCSSquelch, err = parseDumpedCode(value)
+			}
+			if err != nil {
+				return Channel{}, fmt.Errorf("cannot parse %s of memory channel: %w", match[1], err)
+			}
+		}
+	}
+	if !found {
+		return Channel{}, fmt.Errorf("%w: no memory channel found in response", protocol.ErrProtocolError)
+	}
+	if result.Mode == "None" {
+		result.Mode = ModeNone
+	}
+	if result.TXMode == "None" {
+		result.TXMode = ModeNone
+	}
+	return result, nil
+}
+
+// parseFrequencyWithUnit parses frequencies like "145.5 MHz" or "+600 kHz".
+func parseFrequencyWithUnit(s string) (Frequency, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(strings.TrimPrefix(fields[0], "+"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "GHz":
+			value *= 1000000000
+		case "MHz":
+			value *= 1000000
+		case "kHz":
+			value *= 1000
+		}
+	}
+	return Frequency(value), nil
+}
+
+// parseDumpedTone parses tones like "88.5Hz".
+func parseDumpedTone(s string) (CTCSSTone, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "Hz"), 64)
+	return CTCSSTone(value), err
+}
+
+// parseDumpedCode parses DCS codes, which are dumped like tones with a decimal point, e.g. "2.3" for D023.
+func parseDumpedCode(s string) (DCSCode, error) {
+	value, err := strconv.Atoi(strings.ReplaceAll(s, ".", ""))
+	return DCSCode(value), err
+}
+
+/*
+	CHIRP CSV
+*/
+
+var chirpHeader = []string{
+	"Location", "Name", "Frequency", "Duplex", "Offset", "Tone", "rToneFreq", "cToneFreq", "DtcsCode", "DtcsPolarity",
+	"RxDtcsCode", "CrossMode", "Mode", "TStep", "Skip", "Power", "Comment", "URCALL", "RPT1CALL", "RPT2CALL", "DVCODE",
+}
+
+const defaultChirpTone = CTCSSTone(88.5)
+const defaultChirpCode = DCSCode(23)
+
+var chirpModes = map[Mode]string{
+	Mode("FMN"): "NFM",
+	ModePKTFM:   "FM",
+	ModePKTUSB:  "USB",
+	ModePKTLSB:  "LSB",
+}
+
+var hamlibModes = map[string]Mode{
+	"NFM": Mode("FMN"),
+	"NAM": ModeAM,
+	"DIG": ModePKTUSB,
+}
+
+// WriteChannelsCSV writes the given channels in the CSV format of CHIRP.
+func WriteChannelsCSV(w io.Writer, channels []Channel) error {
+	out := csv.NewWriter(w)
+	err := out.Write(chirpHeader)
+	if err != nil {
+		return err
+	}
+	for _, channel := range channels {
+		err := out.Write(chirpRecord(channel))
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func chirpRecord(channel Channel) []string {
+	duplex := ""
+	offset := channel.RepeaterOffset
+	switch {
+	case channel.Split:
+		duplex = "split"
+		offset = channel.TXFrequency
+	case channel.RepeaterShift == RepeaterShiftPlus || channel.RepeaterShift == RepeaterShiftMinus:
+		duplex = string(channel.RepeaterShift)
+	default:
+		offset = 0
+	}
+
+	tone := ""
+	rTone, cTone := defaultChirpTone, defaultChirpTone
+	code, rxCode := defaultChirpCode, defaultChirpCode
+	switch {
+	case channel.CTCSSSquelch != 0:
+		tone = "TSQL"
+		cTone = channel.CTCSSSquelch
+		if channel.CTCSSTone != 0 {
+			rTone = channel.CTCSSTone
+		}
+	case channel.CTCSSTone != 0:
+		tone = "Tone"
+		rTone = channel.CTCSSTone
+	case channel.DCSCode != 0 || channel.DCSSquelch != 0:
+		tone = "DTCS"
+		if channel.DCSCode != 0 {
+			code = channel.DCSCode
+		}
+		rxCode = code
+		if channel.DCSSquelch != 0 {
+			rxCode = channel.DCSSquelch
+		}
+	}
+
+	mode, ok := chirpModes[channel.Mode]
+	if !ok {
+		mode = string(channel.Mode)
+	}
+	step := channel.TuningStep
+	if step == 0 {
+		step = 5000
+	}
+
+	return []string{
+		strconv.Itoa(channel.Number),
+		channel.Name,
+		formatMHz(channel.Frequency),
+		duplex,
+		formatMHz(offset),
+		tone,
+		fmt.Sprintf("%.1f", float64(rTone)),
+		fmt.Sprintf("%.1f", float64(cTone)),
+		fmt.Sprintf("%03d", int(code)),
+		"NN",
+		fmt.Sprintf("%03d", int(rxCode)),
+		"Tone->Tone",
+		mode,
+		fmt.Sprintf("%.2f", float64(step)/1000),
+		"", "", "", "", "", "", "",
+	}
+}
+
+func formatMHz(f Frequency) string {
+	return fmt.Sprintf("%.6f", float64(f)/1000000)
+}
+
+// ReadChannelsCSV reads channels from the CSV format of CHIRP.
+func ReadChannelsCSV(r io.Reader) ([]Channel, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+
+	header, err := in.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["Frequency"]; !ok {
+		return nil, errors.New("CSV contains no Frequency column")
+	}
+
+	var result []Channel
+	for {
+		record, err := in.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		channel, err := parseChirpRecord(record, columns)
+		if err != nil {
+			line, _ := in.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		result = append(result, channel)
+	}
+}
+
+func parseChirpRecord(record []string, columns map[string]int) (Channel, error) {
+	value := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var err error
+	var result Channel
+
+	result.Number, err = strconv.Atoi(value("Location"))
+	if err != nil {
+		return Channel{}, fmt.Errorf("invalid location: %w", err)
+	}
+	result.Name = value("Name")
+	result.Frequency, err = parseMHz(value("Frequency"))
+	if err != nil {
+		return Channel{}, fmt.Errorf("invalid frequency: %w", err)
+	}
+	offset, err := parseMHz(value("Offset"))
+	if err != nil {
+		return Channel{}, fmt.Errorf("invalid offset: %w", err)
+	}
+
+	result.RepeaterShift = RepeaterShiftNone
+	switch value("Duplex") {
+	case "+", "-":
+		result.RepeaterShift = RepeaterShift(value("Duplex"))
+		result.RepeaterOffset = offset
+	case "split":
+		result.Split = true
+		result.TXFrequency = offset
+	}
+
+	switch value("Tone") {
+	case "Tone":
+		result.CTCSSTone, err = parseChirpTone(value("rToneFreq"))
+	case "TSQL":
+		result.CTCSSTone, err = parseChirpTone(value("cToneFreq"))
+		if err == nil {
+			result.CTCSSSquelch = result.CTCSSTone
+		}
+	case "DTCS":
+		result.DCSCode, err = parseChirpCode(value("DtcsCode"))
+		if err == nil {
+			result.DCSSquelch, err = parseChirpCode(value("RxDtcsCode"))
+		}
+		if err == nil && result.DCSSquelch == 0 {
+			result.DCSSquelch = result.DCSCode
+		}
+	}
+	if err != nil {
+		return Channel{}, fmt.Errorf("invalid tone: %w", err)
+	}
+
+	mode := value("Mode")
+	if m, ok := hamlibModes[mode]; ok {
+		result.Mode = m
+	} else {
+		result.Mode = Mode(mode)
+	}
+
+	if step := value("TStep"); step != "" {
+		kHz, err := strconv.ParseFloat(step, 64)
+		if err != nil {
+			return Channel{}, fmt.Errorf("invalid tuning step: %w", err)
+		}
+		result.TuningStep = Frequency(kHz * 1000)
+	}
+
+	return result, nil
+}
+
+func parseMHz(s string) (Frequency, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mhz, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return Frequency(mhz * 1000000), nil
+}
+
+func parseChirpTone(s string) (CTCSSTone, error) {
+	if s == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	return CTCSSTone(value), err
+}
+
+func parseChirpCode(s string) (DCSCode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(s)
+	return DCSCode(value), err
+}
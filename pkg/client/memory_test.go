@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannel(t *testing.T) {
+	lines := []string{
+		"Channel: 5, Name: 'DB0XYZ Berlin'",
+		"Freq:   145.6 MHz\tMode:   FM\tWidth:  15 kHz",
+		"txFreq: 0 Hz\ttxMode: None\ttxWidth: 0 Hz",
+		"Shift: -, Offset: -600 kHz, Step: 12.5 kHz, RIT: 0 Hz, XIT: 0 Hz",
+		"CTCSS: 88.5Hz, CTCSSsql: 0Hz, DCS: 2.3, DCSsql: 0.0",
+		"Split: OFF",
+	}
+
+	actual, err := parseChannel(lines)
+	require.NoError(t, err)
+
+	assert.Equal(t, Channel{
+		Number:         5,
+		Name:           "DB0XYZ Berlin",
+		Frequency:      145600000,
+		Mode:           ModeFM,
+		Width:          15000,
+		TXMode:         ModeNone,
+		RepeaterShift:  RepeaterShiftMinus,
+		RepeaterOffset: 600000,
+		TuningStep:     12500,
+		CTCSSTone:      88.5,
+		DCSCode:        23,
+	}, actual)
+}
+
+func TestParseMemoryCaps(t *testing.T) {
+	lines := []string{
+		"Memories: 2",
+		"\t1..99:   \tMEM",
+		"\t  Mem caps: FREQ MODE WIDTH RPTRSHIFT RPTROFS TONE CTCSS NAME",
+		"\t100..101:   \tEDGE",
+		"\t  Mem caps: FREQ MODE",
+	}
+
+	actual := parseMemoryCaps(lines)
+
+	assert.Equal(t, []MemoryCaps{
+		{From: 1, To: 99, Fields: []string{"FREQ", "MODE", "WIDTH", "RPTRSHIFT", "RPTROFS", "TONE", "CTCSS", "NAME"}},
+		{From: 100, To: 101, Fields: []string{"FREQ", "MODE"}},
+	}, actual)
+}
+
+func TestFormatChannel(t *testing.T) {
+	channel := Channel{
+		Number:         5,
+		Name:           "DB0XYZ Berlin",
+		Frequency:      145600000,
+		Mode:           ModeFM,
+		Width:          15000,
+		RepeaterShift:  RepeaterShiftMinus,
+		RepeaterOffset: 600000,
+		CTCSSTone:      88.5,
+	}
+
+	actual := formatChannel(channel, []string{"FREQ", "MODE", "WIDTH", "RPTRSHIFT", "RPTROFS", "TONE", "CTCSS", "NAME"})
+
+	assert.Equal(t, "5 145600000 FM 15000 - 600000 885 0 DB0XYZ_Berlin", actual)
+}
+
+func TestChannelsCSVRoundtrip(t *testing.T) {
+	channels := []Channel{
+		{
+			Number:         1,
+			Name:           "DB0XYZ",
+			Frequency:      145600000,
+			Mode:           ModeFM,
+			RepeaterShift:  RepeaterShiftMinus,
+			RepeaterOffset: 600000,
+			TuningStep:     12500,
+			CTCSSTone:      88.5,
+		},
+		{
+			Number:        2,
+			Name:          "Simplex",
+			Frequency:     145500000,
+			Mode:          Mode("FMN"),
+			RepeaterShift: RepeaterShiftNone,
+			TuningStep:    12500,
+		},
+		{
+			Number:        3,
+			Name:          "DCS",
+			Frequency:     439100000,
+			Mode:          ModeFM,
+			RepeaterShift: RepeaterShiftNone,
+			TuningStep:    25000,
+			DCSCode:       23,
+			DCSSquelch:    23,
+		},
+	}
+	buffer := new(bytes.Buffer)
+
+	err := WriteChannelsCSV(buffer, channels)
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "2,Simplex,145.500000,,0.000000,,88.5,88.5,023,NN,023,Tone->Tone,NFM,12.50")
+
+	actual, err := ReadChannelsCSV(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, channels, actual)
+}
@@ -0,0 +1,350 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// DefaultMorseChunkSize is the default maximum length of the text chunks that the MorseQueue sends at once.
+const DefaultMorseChunkSize = 24
+
+// fallbackMorseSpeed is the speed in wpm that is assumed to estimate the keying time if the connected radio neither
+// supports wait_morse nor reports its keying speed.
+const fallbackMorseSpeed = 20
+
+// MorseEvent reports the completion of a message in the MorseQueue.
+type MorseEvent struct {
+	ID      int
+	Text    string
+	Aborted bool
+	Err     error
+}
+
+// MorseQueue sends morse messages through the connected radio one after the other. Long messages are split into
+// chunks of words. After each chunk, the queue waits with wait_morse until the radio has keyed the chunk. Since
+// wait_morse blocks the connection until the chunk is sent completely, the queue sends it through a dedicated
+// connection to the same server. If the radio does not support wait_morse, the queue waits for the time the radio
+// needs to key the chunk at its current speed instead. Abort stops the current chunk immediately.
+type MorseQueue struct {
+	conn      *Conn
+	chunkSize int
+	onEvent   func(MorseEvent)
+
+	waitConn *Conn
+	estimate bool
+
+	lock      *sync.Mutex
+	pending   []MorseEvent
+	nextID    int
+	aborted   int
+	signal    chan struct{}
+	interrupt chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewMorseQueue creates a new MorseQueue for this connection. The given callback is called for each message when it
+// was sent completely, was aborted or failed. The queue stops when it is closed or when the connection is closed.
+func (c *Conn) NewMorseQueue(onEvent func(MorseEvent)) *MorseQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := &MorseQueue{
+		conn:      c,
+		chunkSize: DefaultMorseChunkSize,
+		onEvent:   onEvent,
+		lock:      new(sync.Mutex),
+		signal:    make(chan struct{}, 1),
+		interrupt: make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	c.WhenClosed(cancel)
+	go result.run()
+	return result
+}
+
+// SetChunkSize sets the maximum length of the text chunks that are sent at once.
+func (q *MorseQueue) SetChunkSize(size int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if size > 0 {
+		q.chunkSize = size
+	}
+}
+
+// Send adds the given text to the queue and returns the ID of the new message.
+func (q *MorseQueue) Send(text string) int {
+	q.lock.Lock()
+	q.nextID++
+	id := q.nextID
+	q.pending = append(q.pending, MorseEvent{ID: id, Text: text})
+	q.lock.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return id
+}
+
+// Pending returns the number of messages that wait to be sent.
+func (q *MorseQueue) Pending() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.pending)
+}
+
+// Abort drops all pending messages and stops the current transmission.
+func (q *MorseQueue) Abort(ctx context.Context) error {
+	q.lock.Lock()
+	dropped := q.pending
+	q.pending = nil
+	q.aborted++
+	q.lock.Unlock()
+
+	select {
+	case q.interrupt <- struct{}{}:
+	default:
+	}
+
+	for _, message := range dropped {
+		message.Aborted = true
+		q.emit(message)
+	}
+	return q.conn.StopMorse(ctx)
+}
+
+// Close stops the queue. Pending messages are dropped without events.
+func (q *MorseQueue) Close() {
+	q.cancel()
+}
+
+func (q *MorseQueue) run() {
+	defer func() {
+		if q.waitConn != nil {
+			q.waitConn.Close()
+		}
+	}()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-q.signal:
+		}
+
+		for {
+			message, chunkSize, abortCount, ok := q.next()
+			if !ok {
+				break
+			}
+			message.Aborted, message.Err = q.send(message.Text, chunkSize, abortCount)
+			if q.ctx.Err() != nil {
+				return
+			}
+			q.emit(message)
+		}
+	}
+}
+
+func (q *MorseQueue) next() (MorseEvent, int, int, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if len(q.pending) == 0 {
+		return MorseEvent{}, 0, 0, false
+	}
+	result := q.pending[0]
+	q.pending = q.pending[1:]
+	return result, q.chunkSize, q.aborted, true
+}
+
+func (q *MorseQueue) abortedSince(abortCount int) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.aborted != abortCount
+}
+
+func (q *MorseQueue) send(text string, chunkSize int, abortCount int) (bool, error) {
+	var wpm float64
+	for _, chunk := range splitMorse(text, chunkSize) {
+		if q.abortedSince(abortCount) {
+			return true, nil
+		}
+		err := q.conn.SendMorse(q.ctx, chunk)
+		if err != nil {
+			return false, err
+		}
+
+		if !q.estimate {
+			done, err := q.waitMorse(abortCount)
+			if err != nil {
+				return false, err
+			}
+			if !done {
+				return true, nil
+			}
+			if !q.estimate {
+				continue
+			}
+		}
+
+		if wpm == 0 {
+			wpm = q.morseSpeed()
+		}
+		ctx, cancel := context.WithTimeout(q.ctx, morseDuration(chunk, wpm))
+		done := q.wait(ctx.Done(), abortCount)
+		cancel()
+		if !done {
+			return true, nil
+		}
+	}
+	return q.abortedSince(abortCount), nil
+}
+
+// waitMorse waits with wait_morse on the dedicated connection until the radio has keyed the current chunk. It returns
+// false if the queue was aborted or closed in the meantime. If the radio does not support wait_morse or the dedicated
+// connection cannot be opened, the queue switches to estimating the keying time.
+func (q *MorseQueue) waitMorse(abortCount int) (bool, error) {
+	if q.waitConn == nil {
+		conn, err := q.conn.openDedicated(q.ctx)
+		if err != nil {
+			q.conn.log().Warn("cannot open the connection for wait_morse, estimating the keying time", "error", err)
+			q.estimate = true
+			return true, nil
+		}
+		q.waitConn = conn
+	}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+	defer cancel()
+	var err error
+	done := make(chan struct{})
+	go func() {
+		err = q.waitConn.WaitMorse(ctx)
+		close(done)
+	}()
+	if !q.wait(done, abortCount) {
+		return false, nil
+	}
+
+	if errors.Is(err, protocol.ErrFeatureNotAvailable) || errors.Is(err, protocol.ErrFeatureNotImplemented) {
+		q.conn.log().Info("wait_morse is not supported, estimating the keying time")
+		q.estimate = true
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// morseSpeed returns the current keying speed of the radio in wpm, or fallbackMorseSpeed if the radio does not
+// report it.
+func (q *MorseQueue) morseSpeed() float64 {
+	wpm, err := q.conn.MorseSpeed(q.ctx)
+	if err != nil || wpm <= 0 {
+		return fallbackMorseSpeed
+	}
+	return wpm
+}
+
+// wait waits until the given channel is closed. It returns false if the queue was aborted or closed in the meantime.
+func (q *MorseQueue) wait(done <-chan struct{}, abortCount int) bool {
+	for {
+		select {
+		case <-done:
+			return q.ctx.Err() == nil && !q.abortedSince(abortCount)
+		case <-q.ctx.Done():
+			return false
+		case <-q.interrupt:
+			if q.abortedSince(abortCount) {
+				return false
+			}
+		}
+	}
+}
+
+func (q *MorseQueue) emit(event MorseEvent) {
+	if q.onEvent != nil {
+		q.onEvent(event)
+	}
+}
+
+// splitMorse splits the given text into chunks of words with the given maximum length. Words that are longer than
+// the maximum length are split.
+func splitMorse(text string, maxLength int) []string {
+	var result []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > maxLength {
+			if current != "" {
+				result = append(result, current)
+				current = ""
+			}
+			result = append(result, word[:maxLength])
+			word = word[maxLength:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= maxLength:
+			current += " " + word
+		default:
+			result = append(result, current)
+			current = word
+		}
+	}
+	if current != "" {
+		result = append(result, current)
+	}
+	return result
+}
+
+// morseCodes contains the morse code of the characters that can be sent with send_morse.
+var morseCodes = map[rune]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.", 'H': "....", 'I': "..",
+	'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-", 'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-", '5': ".....", '6': "-....", '7': "--...",
+	'8': "---..", '9': "----.",
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '/': "-..-.", '=': "-...-", '+': ".-.-.", '-': "-....-",
+	'@': ".--.-.", '(': "-.--.", ')': "-.--.-", ':': "---...", '\'': ".----.", '"': ".-..-.",
+}
+
+// morseDuration returns the time it takes to key the given text with the given speed in wpm, including the gap to
+// the following word. The timing follows the PARIS standard: a dot is one unit, a dash three units, the gap between
+// the elements of a character is one unit, between characters three units and between words seven units.
+func morseDuration(text string, wpm float64) time.Duration {
+	units := 0
+	for i, word := range strings.Fields(strings.ToUpper(text)) {
+		if i > 0 {
+			units += 7
+		}
+		chars := 0
+		for _, c := range word {
+			code, ok := morseCodes[c]
+			if !ok {
+				continue
+			}
+			if chars > 0 {
+				units += 3
+			}
+			chars++
+			units += len(code) - 1
+			for _, element := range code {
+				if element == '-' {
+					units += 3
+				} else {
+					units++
+				}
+			}
+		}
+	}
+	units += 7
+	unit := time.Duration(float64(1200*time.Millisecond) / wpm)
+	return time.Duration(units) * unit
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMorse(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		text      string
+		maxLength int
+		expected  []string
+	}{
+		{"empty", "", 10, nil},
+		{"short", "cq test", 10, []string{"cq test"}},
+		{"words", "cq test dl1abc dl1abc test", 14, []string{"cq test dl1abc", "dl1abc test"}},
+		{"long word", "tu dl1abc/p 5nn", 4, []string{"tu", "dl1a", "bc/p", "5nn"}},
+		{"extra whitespace", "  cq   test  ", 10, []string{"cq test"}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.expected, splitMorse(tC.text, tC.maxLength))
+		})
+	}
+}
+
+func TestMorseDuration(t *testing.T) {
+	// PARIS takes 50 units including the gap to the next word, one unit is 60ms at 20 wpm.
+	assert.Equal(t, 3*time.Second, morseDuration("paris", 20))
+	assert.Equal(t, 6*time.Second, morseDuration("paris paris", 20))
+	assert.Equal(t, 1500*time.Millisecond, morseDuration("paris", 40))
+}
+
+// morseServer answers the requests of a MorseQueue on all connections and records them.
+type morseServer struct {
+	listener   net.Listener
+	waitResult string
+	waitDelay  time.Duration
+
+	lock     sync.Mutex
+	requests []string
+}
+
+func newMorseServer(t *testing.T, waitResult string, waitDelay time.Duration) *morseServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	result := &morseServer{listener: l, waitResult: waitResult, waitDelay: waitDelay}
+	go result.serve()
+	return result
+}
+
+func (s *morseServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *morseServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimPrefix(strings.TrimSpace(line), "+\\")
+		s.lock.Lock()
+		s.requests = append(s.requests, line)
+		s.lock.Unlock()
+
+		switch {
+		case line == "wait_morse":
+			time.Sleep(s.waitDelay)
+			fmt.Fprintf(conn, "wait_morse:\nRPRT %s\n", s.waitResult)
+		case line == "get_level KEYSPD":
+			fmt.Fprint(conn, "get_level: KEYSPD\n60\nRPRT 0\n")
+		default:
+			fmt.Fprintf(conn, "%s\nRPRT 0\n", line)
+		}
+	}
+}
+
+func (s *morseServer) Requests() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func sendMorseMessage(t *testing.T, server *morseServer) (MorseEvent, time.Duration) {
+	conn, err := Open(server.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	events := make(chan MorseEvent, 1)
+	queue := conn.NewMorseQueue(func(e MorseEvent) { events <- e })
+	defer queue.Close()
+
+	start := time.Now()
+	id := queue.Send("e")
+	select {
+	case event := <-events:
+		assert.Equal(t, id, event.ID)
+		return event, time.Since(start)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "no morse event")
+		return MorseEvent{}, 0
+	}
+}
+
+func TestMorseQueueWaitsWithWaitMorse(t *testing.T) {
+	server := newMorseServer(t, "0", 300*time.Millisecond)
+	defer server.listener.Close()
+
+	event, elapsed := sendMorseMessage(t, server)
+
+	assert.NoError(t, event.Err)
+	assert.False(t, event.Aborted)
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+	assert.Equal(t, []string{"send_morse e", "wait_morse"}, server.Requests())
+}
+
+func TestMorseQueueEstimatesKeyingTimeWithoutWaitMorse(t *testing.T) {
+	server := newMorseServer(t, "-11", 0)
+	defer server.listener.Close()
+
+	event, elapsed := sendMorseMessage(t, server)
+
+	assert.NoError(t, event.Err)
+	assert.False(t, event.Aborted)
+	// "e" takes 8 units including the gap to the next word, one unit is 20ms at 60 wpm
+	assert.GreaterOrEqual(t, elapsed, 160*time.Millisecond)
+	assert.Equal(t, []string{"send_morse e", "wait_morse", "get_level KEYSPD"}, server.Requests())
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -57,14 +57,16 @@ type polling struct {
 	requestsLock *sync.RWMutex
 	requests     []PollRequest
 	done         chan struct{}
+	logger       *slog.Logger
 }
 
-func startPolling(trx *protocol.Transceiver, interval time.Duration, timeout time.Duration, requests []PollRequest) *polling {
+func startPolling(trx *protocol.Transceiver, interval time.Duration, timeout time.Duration, requests []PollRequest, logger *slog.Logger) *polling {
 	result := polling{
 		tick:         time.NewTicker(interval),
 		requestsLock: new(sync.RWMutex),
 		requests:     requests,
 		done:         make(chan struct{}),
+		logger:       logger,
 	}
 
 	go func() {
@@ -96,21 +98,21 @@ func (p *polling) stop() {
 
 func (p *polling) poll(trx *protocol.Transceiver, timeout time.Duration, requests []PollRequest) {
 	for _, pollRequest := range requests {
-		ctx, _ := context.WithTimeout(context.Background(), timeout)
-		request := protocol.Request{Command: pollRequest.Command, Args: pollRequest.Args}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		request := protocol.Request{Command: pollRequest.Command, Args: pollRequest.Args, Priority: protocol.PriorityPoll}
 		response, err := trx.Send(ctx, request)
+		cancel()
+		if err == nil {
+			err = protocol.ResultError(request.Key(), response.Result)
+		}
 		if err != nil {
-			log.Printf("sending poll request %s failed: %v", pollRequest.Command.Long, err)
+			p.logger.Warn("sending poll request failed", "command", request.Key(), "error", err)
 			if errors.Is(err, protocol.ErrFeatureNotAvailable) || errors.Is(err, protocol.ErrFeatureNotImplemented) || errors.Is(err, protocol.ErrFunctionDeprecated) {
-				log.Printf("deactivating poll request %s: %v", pollRequest.Command.Long, err)
-				p.remove(pollRequest.Command)
+				p.logger.Info("deactivating poll request", "command", request.Key(), "error", err)
+				p.remove(pollRequest.Command, pollRequest.Args)
 			}
 			continue
 		}
-		if response.Result != "0" {
-			log.Printf("poll request %s failed with result: %s", pollRequest.Command.Long, response.Result)
-			continue
-		}
 		pollRequest.Handler.Handle(response)
 	}
 }
@@ -120,7 +122,7 @@ func (p *polling) add(request PollRequest) {
 	defer p.requestsLock.Unlock()
 
 	for i, pollRequest := range p.requests {
-		if pollRequest.Command == request.Command {
+		if pollRequest.Command == request.Command && sameArgs(pollRequest.Args, request.Args) {
 			p.requests[i] = request
 			return
 		}
@@ -129,12 +131,25 @@ func (p *polling) add(request PollRequest) {
 	p.requests = append(p.requests, request)
 }
 
-func (p *polling) remove(command protocol.Command) {
+func sameArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// remove the poll request with the given command and arguments.
+func (p *polling) remove(command protocol.Command, args []string) {
 	p.requestsLock.Lock()
 	defer p.requestsLock.Unlock()
 
 	for i, pollRequest := range p.requests {
-		if pollRequest.Command != command {
+		if pollRequest.Command != command || !sameArgs(pollRequest.Args, args) {
 			continue
 		}
 
@@ -145,6 +160,20 @@ func (p *polling) remove(command protocol.Command) {
 	}
 }
 
+// removeAll removes all poll requests with the given command, regardless of their arguments.
+func (p *polling) removeAll(command protocol.Command) {
+	p.requestsLock.Lock()
+	defer p.requestsLock.Unlock()
+
+	requests := make([]PollRequest, 0, len(p.requests))
+	for _, pollRequest := range p.requests {
+		if pollRequest.Command != command {
+			requests = append(requests, pollRequest)
+		}
+	}
+	p.requests = requests
+}
+
 // StartPolling the connected rigctld server with the given interval and timeout and the given set of requests.
 // Poll requests can be added and removed on demand using AddPolls and RemovePolls.
 func (c *Conn) StartPolling(interval time.Duration, timeout time.Duration, requests ...PollRequest) error {
@@ -152,7 +181,7 @@ func (c *Conn) StartPolling(interval time.Duration, timeout time.Duration, reque
 		return fmt.Errorf("polling is already active")
 	}
 
-	c.polling = startPolling(c.trx, interval, timeout, requests)
+	c.polling = startPolling(c.trx, interval, timeout, requests, c.log())
 	return nil
 }
 
@@ -183,13 +212,23 @@ func (c *Conn) AddPolls(requests ...PollRequest) {
 	}
 }
 
-// Remove the poll requests with the given command from the list of poll requests.
+// Remove the poll requests with the given command from the list of poll requests, regardless of their arguments.
 func (c *Conn) RemovePolls(commands ...protocol.Command) {
 	if c.polling == nil {
 		return
 	}
 
 	for _, command := range commands {
-		c.polling.remove(command)
+		c.polling.removeAll(command)
 	}
 }
+
+// RemovePoll removes the poll request with the given command and arguments from the list of poll requests, e.g.
+// RemovePoll(protocol.LongCommand("get_level"), "SWR") removes only the polling of the SWR level.
+func (c *Conn) RemovePoll(command protocol.Command, args ...string) {
+	if c.polling == nil {
+		return
+	}
+
+	c.polling.remove(command, args)
+}
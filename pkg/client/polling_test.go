@@ -0,0 +1,34 @@
+package client
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func pollCommands(p *polling) []string {
+	var result []string
+	for _, request := range p.requests {
+		result = append(result, strings.Join(append([]string{request.Command.Long}, request.Args...), " "))
+	}
+	return result
+}
+
+func TestPollingRemoveComparesArgs(t *testing.T) {
+	nop := func(protocol.Response) {}
+	p := polling{requestsLock: new(sync.RWMutex)}
+	p.add(PollCommandFunc(nop, "get_level", "SWR"))
+	p.add(PollCommandFunc(nop, "get_level", "STRENGTH"))
+	p.add(PollCommandFunc(nop, "get_level", "RFPOWER_METER"))
+	p.add(PollCommandFunc(nop, "get_freq"))
+
+	p.remove(protocol.LongCommand("get_level"), []string{"STRENGTH"})
+	assert.ElementsMatch(t, []string{"get_level SWR", "get_level RFPOWER_METER", "get_freq"}, pollCommands(&p))
+
+	p.removeAll(protocol.LongCommand("get_level"))
+	assert.Equal(t, []string{"get_freq"}, pollCommands(&p))
+}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ftl/hamradio/bandplan"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+/*
+	Power Conversion
+*/
+
+// powerSteps is the number of power levels that are sampled for a conversion table.
+const powerSteps = 10
+
+type powerConversion struct {
+	lock           *sync.Mutex
+	bandplan       bandplan.Bandplan
+	tables         map[powerTableKey]powerTable
+	levels         map[powerLevelKey]float64
+	linearMaxWatts float64
+}
+
+type powerTableKey struct {
+	band string
+	mode Mode
+}
+
+// powerLevelKey identifies the result of a mW2power conversion.
+type powerLevelKey struct {
+	powerTableKey
+	milliwatts int
+}
+
+type powerSample struct {
+	level float64
+	watts float64
+}
+
+// powerTable contains power samples sorted by level.
+type powerTable []powerSample
+
+func newPowerConversion() *powerConversion {
+	return &powerConversion{
+		lock:     new(sync.Mutex),
+		bandplan: bandplan.IARURegion1,
+		tables:   make(map[powerTableKey]powerTable),
+		levels:   make(map[powerLevelKey]float64),
+	}
+}
+
+// tableKey returns the key of the power table for the given frequency and mode. The tables are kept per band of the
+// bandplan, frequencies outside of the bandplan are grouped per MHz.
+func (p *powerConversion) tableKey(frequency Frequency, mode Mode) powerTableKey {
+	band := p.bandplan.ByFrequency(frequency)
+	if band.Name != bandplan.BandUnknown {
+		return powerTableKey{band: string(band.Name), mode: mode}
+	}
+	return powerTableKey{band: fmt.Sprintf("%dMHz", int(frequency/1000000)), mode: mode}
+}
+
+// SetLinearPowerFallback sets the maximum power in watts that is used for a linear mapping between power level and
+// watts, if the connected radio does not implement power2mW and mW2power. Use 0 to disable the fallback.
+func (c *Conn) SetLinearPowerFallback(maxWatts float64) {
+	c.power.lock.Lock()
+	defer c.power.lock.Unlock()
+	c.power.linearMaxWatts = maxWatts
+}
+
+// ResetPowerTables drops all cached power conversions.
+func (c *Conn) ResetPowerTables() {
+	c.power.lock.Lock()
+	defer c.power.lock.Unlock()
+	c.power.tables = make(map[powerTableKey]powerTable)
+	c.power.levels = make(map[powerLevelKey]float64)
+}
+
+// PowerToWatts converts the given power level (0..1) into watts for the given frequency and mode.
+func (c *Conn) PowerToWatts(ctx context.Context, level float64, frequency Frequency, mode Mode) (float64, error) {
+	err := ValueRatio.Validate(level)
+	if err != nil {
+		return 0, err
+	}
+	table, err := c.powerTable(ctx, frequency, mode)
+	if err != nil {
+		return 0, err
+	}
+	return table.watts(level), nil
+}
+
+// WattsToPower converts the given power in watts into a power level (0..1) for the given frequency and mode. The
+// conversion is done by the connected radio using mW2power, the results are cached per band and mode.
+func (c *Conn) WattsToPower(ctx context.Context, watts float64, frequency Frequency, mode Mode) (float64, error) {
+	if watts < 0 {
+		return 0, fmt.Errorf("%w: negative power %v W", protocol.ErrArgumentOutOfDomain, watts)
+	}
+	milliwatts := int(math.Round(watts * 1000))
+	key := powerLevelKey{powerTableKey: c.power.tableKey(frequency, mode), milliwatts: milliwatts}
+	c.power.lock.Lock()
+	level, ok := c.power.levels[key]
+	maxWatts := c.power.linearMaxWatts
+	c.power.lock.Unlock()
+	if ok {
+		return level, nil
+	}
+
+	response, err := c.get(ctx, "mW2power", strconv.Itoa(milliwatts), fmt.Sprintf("%d", int(frequency)), string(mode))
+	if isNotSupported(err) && maxWatts > 0 {
+		return linearPowerLevel(watts, maxWatts), nil
+	} else if err != nil {
+		return 0, err
+	}
+	level, err = strconv.ParseFloat(response.Data[0], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	c.power.lock.Lock()
+	c.power.levels[key] = level
+	c.power.lock.Unlock()
+	return level, nil
+}
+
+// PowerWatts returns the current transmit power setting of the connected radio in watts.
+func (c *Conn) PowerWatts(ctx context.Context) (float64, error) {
+	level, err := c.PowerLevel(ctx)
+	if err != nil {
+		return 0, err
+	}
+	frequency, mode, err := c.frequencyAndMode(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return c.PowerToWatts(ctx, level, frequency, mode)
+}
+
+// SetPowerWatts sets the transmit power of the connected radio in watts, using the conversion for the current
+// frequency and mode.
+func (c *Conn) SetPowerWatts(ctx context.Context, watts float64) error {
+	frequency, mode, err := c.frequencyAndMode(ctx)
+	if err != nil {
+		return err
+	}
+	level, err := c.WattsToPower(ctx, watts, frequency, mode)
+	if err != nil {
+		return err
+	}
+	return c.SetPowerLevel(ctx, level)
+}
+
+func (c *Conn) frequencyAndMode(ctx context.Context) (Frequency, Mode, error) {
+	frequency, err := c.Frequency(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	mode, _, err := c.ModeAndPassband(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	return frequency, mode, nil
+}
+
+func (c *Conn) powerTable(ctx context.Context, frequency Frequency, mode Mode) (powerTable, error) {
+	key := c.power.tableKey(frequency, mode)
+	c.power.lock.Lock()
+	table, ok := c.power.tables[key]
+	maxWatts := c.power.linearMaxWatts
+	c.power.lock.Unlock()
+	if ok {
+		return table, nil
+	}
+
+	table, err := c.samplePowerTable(ctx, frequency, mode)
+	if isNotSupported(err) && maxWatts > 0 {
+		table = linearPowerTable(maxWatts)
+	} else if err != nil {
+		return nil, err
+	}
+
+	c.power.lock.Lock()
+	c.power.tables[key] = table
+	c.power.lock.Unlock()
+	return table, nil
+}
+
+func (c *Conn) samplePowerTable(ctx context.Context, frequency Frequency, mode Mode) (powerTable, error) {
+	result := make(powerTable, 0, powerSteps+1)
+	for i := 0; i <= powerSteps; i++ {
+		level := float64(i) / powerSteps
+		response, err := c.get(ctx, "power2mW", fmt.Sprintf("%f", level), fmt.Sprintf("%d", int(frequency)), string(mode))
+		if err != nil {
+			return nil, err
+		}
+		milliwatts, err := strconv.ParseFloat(response.Data[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, powerSample{level: level, watts: milliwatts / 1000})
+	}
+	return result, nil
+}
+
+func isNotSupported(err error) bool {
+	return errors.Is(err, protocol.ErrFeatureNotImplemented) || errors.Is(err, protocol.ErrFeatureNotAvailable)
+}
+
+func linearPowerTable(maxWatts float64) powerTable {
+	return powerTable{{level: 0, watts: 0}, {level: 1, watts: maxWatts}}
+}
+
+// watts interpolates the power in watts for the given level.
+func (t powerTable) watts(level float64) float64 {
+	i := sort.Search(len(t), func(i int) bool { return t[i].level >= level })
+	switch {
+	case i == 0:
+		return t[0].watts
+	case i == len(t):
+		return t[len(t)-1].watts
+	}
+	lower, upper := t[i-1], t[i]
+	return lower.watts + (level-lower.level)*(upper.watts-lower.watts)/(upper.level-lower.level)
+}
+
+// linearPowerLevel maps the given power in watts linearly to a power level between 0 and maxWatts.
+func linearPowerLevel(watts float64, maxWatts float64) float64 {
+	return min(watts/maxWatts, 1)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/bandplans"
+)
+
+func TestPowerTableInterpolation(t *testing.T) {
+	table := powerTable{
+		{level: 0, watts: 5},
+		{level: 0.5, watts: 50},
+		{level: 1, watts: 100},
+	}
+
+	testCases := []struct {
+		desc  string
+		level float64
+		watts float64
+	}{
+		{"minimum", 0, 5},
+		{"sample", 0.5, 50},
+		{"between samples", 0.25, 27.5},
+		{"maximum", 1, 100},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.InDelta(t, tC.watts, table.watts(tC.level), 0.0001)
+		})
+	}
+}
+
+func TestLinearPowerTable(t *testing.T) {
+	table := linearPowerTable(100)
+
+	assert.InDelta(t, 25, table.watts(0.25), 0.0001)
+	assert.InDelta(t, 0.4, linearPowerLevel(40, 100), 0.0001)
+	assert.Equal(t, 1.0, linearPowerLevel(200, 100), "above maximum")
+}
+
+func TestPowerTableKey(t *testing.T) {
+	power := newPowerConversion()
+	assert.Equal(t, powerTableKey{band: "20m", mode: ModeUSB}, power.tableKey(14074000, ModeUSB))
+	assert.Equal(t, powerTableKey{band: "145MHz", mode: ModeFM}, power.tableKey(145500000, ModeFM))
+	assert.Equal(t, powerTableKey{band: "7MHz", mode: ModeLSB}, power.tableKey(7250000, ModeLSB))
+
+	WithBandplan(bandplans.IARURegion2)(&Conn{power: power})
+	assert.Equal(t, powerTableKey{band: "40m", mode: ModeLSB}, power.tableKey(7250000, ModeLSB))
+}
+
+func TestWattsToPowerUsesMW2Power(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	requests := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			requests <- line
+			fmt.Fprint(conn, "mW2power: 50000 14074000 USB\nPower [0.0..1.0]: 0.45\nRPRT 0\n")
+		}
+	}()
+
+	conn, err := Open(l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for range 2 {
+		level, err := conn.WattsToPower(context.Background(), 50, 14074000, ModeUSB)
+		require.NoError(t, err)
+		assert.Equal(t, 0.45, level)
+	}
+
+	assert.Equal(t, "+\\mW2power 50000 14074000 USB\n", <-requests)
+	assert.Empty(t, requests, "the conversion is cached")
+}
@@ -0,0 +1,359 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// RepeaterShift is the direction of the repeater shift.
+type RepeaterShift string
+
+const (
+	RepeaterShiftNone  RepeaterShift = "None"
+	RepeaterShiftPlus  RepeaterShift = "+"
+	RepeaterShiftMinus RepeaterShift = "-"
+)
+
+// Validate checks if this is a known repeater shift.
+func (s RepeaterShift) Validate() error {
+	switch s {
+	case RepeaterShiftNone, RepeaterShiftPlus, RepeaterShiftMinus:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown repeater shift %q", protocol.ErrArgumentOutOfDomain, s)
+	}
+}
+
+// CTCSSTone is a CTCSS tone frequency in Hz, 0 means off.
+type CTCSSTone float64
+
+// CTCSSTones contains the 50 standard CTCSS tones in Hz.
+var CTCSSTones = []CTCSSTone{
+	67.0, 69.3, 71.9, 74.4, 77.0, 79.7, 82.5, 85.4, 88.5, 91.5,
+	94.8, 97.4, 100.0, 103.5, 107.2, 110.9, 114.8, 118.8, 123.0, 127.3,
+	131.8, 136.5, 141.3, 146.2, 151.4, 156.7, 159.8, 162.2, 165.5, 167.9,
+	171.3, 173.8, 177.3, 179.9, 183.5, 186.2, 189.9, 192.8, 196.6, 199.5,
+	203.5, 206.5, 210.7, 218.1, 225.7, 229.1, 233.6, 241.8, 250.3, 254.1,
+}
+
+// Validate checks if this is one of the standard CTCSS tones or off.
+func (t CTCSSTone) Validate() error {
+	if t == 0 {
+		return nil
+	}
+	for _, tone := range CTCSSTones {
+		if tone.hamlib() == t.hamlib() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %.1f Hz is not a standard CTCSS tone", protocol.ErrArgumentOutOfDomain, float64(t))
+}
+
+func (t CTCSSTone) hamlib() string {
+	return strconv.Itoa(int(math.Round(float64(t) * 10)))
+}
+
+func parseCTCSSTone(s string) (CTCSSTone, error) {
+	tenths, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	return CTCSSTone(float64(tenths) / 10), nil
+}
+
+// DCSCode is a DCS code, 0 means off. The code is written with the digits of its octal notation, e.g. 23 for D023.
+type DCSCode int
+
+// DCSCodes contains the 104 standard DCS codes.
+var DCSCodes = []DCSCode{
+	23, 25, 26, 31, 32, 36, 43, 47, 51, 53, 54, 65, 71, 72, 73, 74,
+	114, 115, 116, 122, 125, 131, 132, 134, 143, 145, 152, 155, 156, 162, 165, 172, 174,
+	205, 212, 223, 225, 226, 243, 244, 245, 246, 251, 252, 255, 261, 263, 265, 266, 271, 274,
+	306, 311, 315, 325, 331, 332, 343, 346, 351, 356, 364, 365, 371,
+	411, 412, 413, 423, 431, 432, 445, 446, 452, 454, 455, 462, 464, 465, 466,
+	503, 506, 516, 523, 526, 532, 546, 565,
+	606, 612, 624, 627, 631, 632, 654, 662, 664,
+	703, 712, 723, 731, 732, 734, 743, 754,
+}
+
+// Validate checks if this is one of the standard DCS codes or off.
+func (c DCSCode) Validate() error {
+	if c == 0 {
+		return nil
+	}
+	for _, code := range DCSCodes {
+		if code == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: D%03d is not a standard DCS code", protocol.ErrArgumentOutOfDomain, int(c))
+}
+
+func (c DCSCode) hamlib() string {
+	return strconv.Itoa(int(c))
+}
+
+func parseDCSCode(s string) (DCSCode, error) {
+	code, err := strconv.Atoi(s)
+	return DCSCode(code), err
+}
+
+func validateRepeaterOffset(offset Frequency) error {
+	if offset < 0 {
+		return fmt.Errorf("%w: negative repeater offset %v, use the repeater shift for the direction", protocol.ErrArgumentOutOfDomain, offset)
+	}
+	return nil
+}
+
+/*
+	Repeater Shift and Offset
+*/
+
+// RepeaterShift returns the current repeater shift of the connected radio.
+func (c *Conn) RepeaterShift(ctx context.Context) (RepeaterShift, error) {
+	response, err := c.get(ctx, "get_rptr_shift")
+	if err != nil {
+		return RepeaterShiftNone, err
+	}
+	return parseRepeaterShift(response.Data[0]), nil
+}
+
+// OnRepeaterShift wraps the given callback function into the ResponseHandler interface and translates the generic response to a RepeaterShift value.
+func OnRepeaterShift(callback func(RepeaterShift)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		callback(parseRepeaterShift(r.Data[0]))
+	}), "get_rptr_shift"
+}
+
+func parseRepeaterShift(s string) RepeaterShift {
+	switch RepeaterShift(s) {
+	case RepeaterShiftPlus, RepeaterShiftMinus:
+		return RepeaterShift(s)
+	default:
+		return RepeaterShiftNone
+	}
+}
+
+// SetRepeaterShift sets the repeater shift of the connected radio.
+func (c *Conn) SetRepeaterShift(ctx context.Context, shift RepeaterShift) error {
+	err := shift.Validate()
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_rptr_shift", repeaterShiftArg(shift))
+}
+
+// RepeaterOffset returns the current repeater offset in Hz of the connected radio.
+func (c *Conn) RepeaterOffset(ctx context.Context) (Frequency, error) {
+	response, err := c.get(ctx, "get_rptr_offs")
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.ParseFloat(response.Data[0], 64)
+	return Frequency(offset), err
+}
+
+// OnRepeaterOffset wraps the given callback function into the ResponseHandler interface and translates the generic response to the repeater offset.
+func OnRepeaterOffset(callback func(Frequency)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		offset, err := strconv.ParseFloat(r.Data[0], 64)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse repeater offset result", "error", err)
+			return
+		}
+		callback(Frequency(offset))
+	}), "get_rptr_offs"
+}
+
+// SetRepeaterOffset sets the repeater offset in Hz of the connected radio. The direction of the offset is given by the repeater shift.
+func (c *Conn) SetRepeaterOffset(ctx context.Context, offset Frequency) error {
+	err := validateRepeaterOffset(offset)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_rptr_offs", fmt.Sprintf("%d", int(offset)))
+}
+
+/*
+	CTCSS and DCS
+*/
+
+// CTCSSTone returns the current CTCSS encoder tone of the connected radio.
+func (c *Conn) CTCSSTone(ctx context.Context) (CTCSSTone, error) {
+	response, err := c.get(ctx, "get_ctcss_tone")
+	if err != nil {
+		return 0, err
+	}
+	return parseCTCSSTone(response.Data[0])
+}
+
+// OnCTCSSTone wraps the given callback function into the ResponseHandler interface and translates the generic response to the CTCSS encoder tone.
+func OnCTCSSTone(callback func(CTCSSTone)) (ResponseHandler, string) {
+	return onCTCSSTone(callback), "get_ctcss_tone"
+}
+
+// SetCTCSSTone sets the CTCSS encoder tone of the connected radio. Use 0 to switch the tone off.
+func (c *Conn) SetCTCSSTone(ctx context.Context, tone CTCSSTone) error {
+	err := tone.Validate()
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_ctcss_tone", tone.hamlib())
+}
+
+// CTCSSSquelch returns the current CTCSS squelch tone of the connected radio.
+func (c *Conn) CTCSSSquelch(ctx context.Context) (CTCSSTone, error) {
+	response, err := c.get(ctx, "get_ctcss_sql")
+	if err != nil {
+		return 0, err
+	}
+	return parseCTCSSTone(response.Data[0])
+}
+
+// OnCTCSSSquelch wraps the given callback function into the ResponseHandler interface and translates the generic response to the CTCSS squelch tone.
+func OnCTCSSSquelch(callback func(CTCSSTone)) (ResponseHandler, string) {
+	return onCTCSSTone(callback), "get_ctcss_sql"
+}
+
+// SetCTCSSSquelch sets the CTCSS squelch tone of the connected radio. Use 0 to switch the squelch off.
+func (c *Conn) SetCTCSSSquelch(ctx context.Context, tone CTCSSTone) error {
+	err := tone.Validate()
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_ctcss_sql", tone.hamlib())
+}
+
+func onCTCSSTone(callback func(CTCSSTone)) ResponseHandler {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		tone, err := parseCTCSSTone(r.Data[0])
+		if err != nil {
+			slog.Warn("hamlib: cannot parse CTCSS tone result", "error", err)
+			return
+		}
+		callback(tone)
+	})
+}
+
+// DCSCode returns the current DCS encoder code of the connected radio.
+func (c *Conn) DCSCode(ctx context.Context) (DCSCode, error) {
+	response, err := c.get(ctx, "get_dcs_code")
+	if err != nil {
+		return 0, err
+	}
+	return parseDCSCode(response.Data[0])
+}
+
+// OnDCSCode wraps the given callback function into the ResponseHandler interface and translates the generic response to the DCS encoder code.
+func OnDCSCode(callback func(DCSCode)) (ResponseHandler, string) {
+	return onDCSCode(callback), "get_dcs_code"
+}
+
+// SetDCSCode sets the DCS encoder code of the connected radio. Use 0 to switch DCS off.
+func (c *Conn) SetDCSCode(ctx context.Context, code DCSCode) error {
+	err := code.Validate()
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_dcs_code", code.hamlib())
+}
+
+// DCSSquelch returns the current DCS squelch code of the connected radio.
+func (c *Conn) DCSSquelch(ctx context.Context) (DCSCode, error) {
+	response, err := c.get(ctx, "get_dcs_sql")
+	if err != nil {
+		return 0, err
+	}
+	return parseDCSCode(response.Data[0])
+}
+
+// OnDCSSquelch wraps the given callback function into the ResponseHandler interface and translates the generic response to the DCS squelch code.
+func OnDCSSquelch(callback func(DCSCode)) (ResponseHandler, string) {
+	return onDCSCode(callback), "get_dcs_sql"
+}
+
+// SetDCSSquelch sets the DCS squelch code of the connected radio. Use 0 to switch the squelch off.
+func (c *Conn) SetDCSSquelch(ctx context.Context, code DCSCode) error {
+	err := code.Validate()
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, "set_dcs_sql", code.hamlib())
+}
+
+func onDCSCode(callback func(DCSCode)) ResponseHandler {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		code, err := parseDCSCode(r.Data[0])
+		if err != nil {
+			slog.Warn("hamlib: cannot parse DCS code result", "error", err)
+			return
+		}
+		callback(code)
+	})
+}
+
+/*
+	Repeater Setup
+*/
+
+// TuneRepeater sets up the connected radio to work through a repeater with the given output frequency, shift, offset
+// and CTCSS tone. All values are validated before anything is changed on the radio. The radio is tuned to the
+// repeater output first and the offset is set before the shift, so the radio never transmits on an unexpected
+// frequency in between. A tone of 0 switches the CTCSS encoder off.
+func (c *Conn) TuneRepeater(ctx context.Context, outputFrequency Frequency, shift RepeaterShift, offset Frequency, tone CTCSSTone) error {
+	if outputFrequency <= 0 {
+		return fmt.Errorf("%w: invalid output frequency %v", protocol.ErrArgumentOutOfDomain, outputFrequency)
+	}
+	err := shift.Validate()
+	if err != nil {
+		return err
+	}
+	err = validateRepeaterOffset(offset)
+	if err != nil {
+		return err
+	}
+	err = tone.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.SetFrequency(ctx, outputFrequency)
+	if err != nil {
+		return err
+	}
+	if shift != RepeaterShiftNone {
+		err = c.SetRepeaterOffset(ctx, offset)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.SetRepeaterShift(ctx, shift)
+	if err != nil {
+		return err
+	}
+	if tone == 0 {
+		return c.SetFunc(ctx, FuncTone, false)
+	}
+	err = c.SetCTCSSTone(ctx, tone)
+	if err != nil {
+		return err
+	}
+	return c.SetFunc(ctx, FuncTone, true)
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestToneAndCodeTables(t *testing.T) {
+	assert.Len(t, CTCSSTones, 50)
+	assert.Len(t, DCSCodes, 104)
+}
+
+func TestValidateCTCSSTone(t *testing.T) {
+	testCases := []struct {
+		tone  CTCSSTone
+		valid bool
+	}{
+		{0, true},
+		{67, true},
+		{88.5, true},
+		{254.1, true},
+		{88, false},
+		{300, false},
+	}
+	for _, tC := range testCases {
+		err := tC.tone.Validate()
+		if tC.valid {
+			assert.NoError(t, err, "%v", tC.tone)
+		} else {
+			assert.True(t, errors.Is(err, protocol.ErrArgumentOutOfDomain), "%v", tC.tone)
+		}
+	}
+}
+
+func TestValidateDCSCode(t *testing.T) {
+	assert.NoError(t, DCSCode(0).Validate())
+	assert.NoError(t, DCSCode(23).Validate())
+	assert.NoError(t, DCSCode(754).Validate())
+	assert.True(t, errors.Is(DCSCode(24).Validate(), protocol.ErrArgumentOutOfDomain))
+}
+
+func TestValidateRepeaterShift(t *testing.T) {
+	assert.NoError(t, RepeaterShiftNone.Validate())
+	assert.NoError(t, RepeaterShiftPlus.Validate())
+	assert.NoError(t, RepeaterShiftMinus.Validate())
+	assert.Error(t, RepeaterShift("up").Validate())
+	assert.Equal(t, RepeaterShiftNone, parseRepeaterShift("None"))
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// RotatorConn represents the Hamlib client connection to a rotctld server.
+type RotatorConn struct {
+	conn *Conn
+}
+
+// OpenRotator opens a client connection to the rotctld server at the given address. If address is empty, "localhost:4533" is used as default.
+func OpenRotator(address string, opts ...Option) (*RotatorConn, error) {
+	if address == "" {
+		address = "localhost:4533"
+	}
+
+	conn, err := open(address, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotatorConn{conn: conn}, nil
+}
+
+// OpenRotatorTLS opens a client connection to the rotctld server or rigproxy at the given address through TLS. If
+// address is empty, "localhost:4533" is used as default.
+func OpenRotatorTLS(address string, config *tls.Config, opts ...Option) (*RotatorConn, error) {
+	return OpenRotator(address, append([]Option{WithTLS(config)}, opts...)...)
+}
+
+// Close the client connection.
+func (r *RotatorConn) Close() {
+	r.conn.Close()
+}
+
+// Closed indicates if this connection is closed.
+func (r *RotatorConn) Closed() bool {
+	return r.conn.Closed()
+}
+
+// WhenClosed will call the given callback asynchronously as soon as this connection is closed.
+func (r *RotatorConn) WhenClosed(f func()) {
+	r.conn.WhenClosed(f)
+}
+
+// Authenticate sends the given password to the server with the password command.
+func (r *RotatorConn) Authenticate(ctx context.Context, password string) error {
+	return r.conn.set(ctx, protocol.RotatorLongCommand("password"), password)
+}
+
+// Set executes the given hamlib rotator set command with the given parameters.
+func (r *RotatorConn) Set(ctx context.Context, longCommandName string, args ...string) error {
+	return r.conn.set(ctx, protocol.RotatorLongCommand(longCommandName), args...)
+}
+
+func (r *RotatorConn) get(ctx context.Context, longCommandName string, args ...string) (protocol.Response, error) {
+	return r.conn.getCommand(ctx, protocol.RotatorLongCommand(longCommandName), args...)
+}
+
+// PollRotatorCommand creates a PollRequest for a rotctld server from the given handler, command name and arguments.
+func PollRotatorCommand(handler ResponseHandler, command string, args ...string) PollRequest {
+	var cmd protocol.Command
+	if len(command) == 1 {
+		cmd = protocol.RotatorShortCommand(command)
+	} else {
+		cmd = protocol.RotatorLongCommand(command)
+	}
+	return PollRequest{
+		Command: cmd,
+		Args:    args,
+		Handler: handler,
+	}
+}
+
+// StartPolling the connected rotctld server with the given interval and timeout and the given set of requests.
+// Use PollRotatorCommand to create the poll requests.
+func (r *RotatorConn) StartPolling(interval time.Duration, timeout time.Duration, requests ...PollRequest) error {
+	return r.conn.StartPolling(interval, timeout, requests...)
+}
+
+// StopPolling stops the polling loop.
+func (r *RotatorConn) StopPolling() {
+	r.conn.StopPolling()
+}
+
+// IsPolling indicates if this connection is polling the rotctld server periodically.
+func (r *RotatorConn) IsPolling() bool {
+	return r.conn.IsPolling()
+}
+
+/*
+	Position
+*/
+
+// Position returns the current azimuth and elevation in degrees of the connected rotator.
+func (r *RotatorConn) Position(ctx context.Context) (float64, float64, error) {
+	response, err := r.get(ctx, "get_pos")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parsePosition(response)
+}
+
+// OnPosition wraps the given callback function into the ResponseHandler interface and translates the generic response to azimuth and elevation.
+func OnPosition(callback func(azimuth, elevation float64)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		azimuth, elevation, err := parsePosition(r)
+		if err != nil {
+			slog.Warn("hamlib: cannot parse position result", "error", err)
+			return
+		}
+		callback(azimuth, elevation)
+	}), "get_pos"
+}
+
+func parsePosition(r protocol.Response) (float64, float64, error) {
+	if len(r.Data) < 2 {
+		return 0, 0, fmt.Errorf("hamlib: incomplete position result: %v", r.Data)
+	}
+	azimuth, err := strconv.ParseFloat(r.Data[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	elevation, err := strconv.ParseFloat(r.Data[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return azimuth, elevation, nil
+}
+
+// SetPosition turns the connected rotator to the given azimuth and elevation in degrees.
+func (r *RotatorConn) SetPosition(ctx context.Context, azimuth, elevation float64) error {
+	return r.Set(ctx, "set_pos", fmt.Sprintf("%f", azimuth), fmt.Sprintf("%f", elevation))
+}
+
+// SetAzimuth turns the connected rotator to the given azimuth in degrees and keeps the current elevation.
+func (r *RotatorConn) SetAzimuth(ctx context.Context, azimuth float64) error {
+	_, elevation, err := r.Position(ctx)
+	if err != nil {
+		return err
+	}
+	return r.SetPosition(ctx, azimuth, elevation)
+}
+
+/*
+	Movement
+*/
+
+// Direction represents the direction of a rotator movement.
+type Direction string
+
+const (
+	DirectionUp        = Direction("2")
+	DirectionDown      = Direction("4")
+	DirectionLeft      = Direction("8")
+	DirectionCCW       = Direction("8")
+	DirectionRight     = Direction("16")
+	DirectionCW        = Direction("16")
+	DirectionUpLeft    = Direction("32")
+	DirectionUpRight   = Direction("64")
+	DirectionDownLeft  = Direction("128")
+	DirectionDownRight = Direction("256")
+)
+
+// Move the connected rotator in the given direction with the given speed (1-100).
+func (r *RotatorConn) Move(ctx context.Context, direction Direction, speed int) error {
+	return r.Set(ctx, "move", string(direction), strconv.Itoa(speed))
+}
+
+// Stop the current movement of the connected rotator.
+func (r *RotatorConn) Stop(ctx context.Context) error {
+	return r.Set(ctx, "stop")
+}
+
+// Park the connected rotator.
+func (r *RotatorConn) Park(ctx context.Context) error {
+	return r.Set(ctx, "park")
+}
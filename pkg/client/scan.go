@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+/*
+	Scan
+*/
+
+// ScanType is a scan function of the connected radio.
+type ScanType string
+
+const (
+	ScanStop       ScanType = "STOP"
+	ScanMemory     ScanType = "MEM"
+	ScanSelected   ScanType = "SLCT"
+	ScanPriority   ScanType = "PRIO"
+	ScanProgram    ScanType = "PROG"
+	ScanDelta      ScanType = "DELTA"
+	ScanVFO        ScanType = "VFO"
+	ScanPLT        ScanType = "PLT"
+	ScanContinuous ScanType = "CONT"
+	ScanTone       ScanType = "TONE"
+)
+
+// Scan starts the given scan function on the connected radio. The channel is used by scan functions that depend on
+// a memory channel, otherwise it is ignored by the radio.
+func (c *Conn) Scan(ctx context.Context, scanType ScanType, channel int) error {
+	return c.Set(ctx, "scan", string(scanType), strconv.Itoa(channel))
+}
+
+// StopScan stops the current scan of the connected radio.
+func (c *Conn) StopScan(ctx context.Context) error {
+	return c.Scan(ctx, ScanStop, 0)
+}
+
+/*
+	DCD
+*/
+
+// DCD indicates if the squelch of the connected radio is open.
+func (c *Conn) DCD(ctx context.Context) (bool, error) {
+	response, err := c.get(ctx, "get_dcd")
+	if err != nil {
+		return false, err
+	}
+	return response.Data[0] == "1", nil
+}
+
+// OnDCD wraps the given callback function into the ResponseHandler interface and translates the generic response to the squelch state.
+func OnDCD(callback func(bool)) (ResponseHandler, string) {
+	return ResponseHandlerFunc(func(r protocol.Response) {
+		if len(r.Data) == 0 {
+			return
+		}
+		callback(r.Data[0] == "1")
+	}), "get_dcd"
+}
+
+/*
+	Software Scanner
+*/
+
+// ErrNoActivity is returned by SoftwareScan if no activity was found in the scanned range.
+var ErrNoActivity = errors.New("no activity found")
+
+// ScanDetection defines how the software scanner detects activity.
+type ScanDetection int
+
+const (
+	// DetectDCD detects activity when the squelch of the radio is open.
+	DetectDCD ScanDetection = iota
+	// DetectStrength detects activity when the signal strength reaches the threshold.
+	DetectStrength
+)
+
+// DefaultScanDwell is the default time the software scanner stays on each frequency.
+const DefaultScanDwell = 250 * time.Millisecond
+
+// ScanConfig configures the software scanner.
+type ScanConfig struct {
+	From  Frequency
+	To    Frequency
+	Step  Frequency
+	Dwell time.Duration
+
+	Detection ScanDetection
+	// Threshold is the signal strength in dB relative to S9 that is detected as activity when using DetectStrength.
+	Threshold float64
+	// Loop restarts the scan at From after reaching To, until activity is found or the context is done.
+	Loop bool
+}
+
+func (c ScanConfig) validate() error {
+	if c.Step <= 0 {
+		return fmt.Errorf("%w: scan step must be positive", protocol.ErrArgumentOutOfDomain)
+	}
+	if c.From <= 0 || c.To < c.From {
+		return fmt.Errorf("%w: invalid scan range %v..%v", protocol.ErrArgumentOutOfDomain, c.From, c.To)
+	}
+	return nil
+}
+
+func (c ScanConfig) steps() int {
+	return int((c.To-c.From)/c.Step) + 1
+}
+
+func (c ScanConfig) frequency(step int) Frequency {
+	return c.From + Frequency(step)*c.Step
+}
+
+// SoftwareScan steps through the configured frequency range on the connected radio and stops on the first frequency
+// with activity. The frequency with activity is returned, the radio stays tuned to this frequency. To resume the scan,
+// call SoftwareScan again with From set above the returned frequency.
+func (c *Conn) SoftwareScan(ctx context.Context, config ScanConfig) (Frequency, error) {
+	err := config.validate()
+	if err != nil {
+		return 0, err
+	}
+	if config.Dwell == 0 {
+		config.Dwell = DefaultScanDwell
+	}
+
+	for {
+		for step := 0; step < config.steps(); step++ {
+			frequency := config.frequency(step)
+			err := c.SetFrequency(ctx, frequency)
+			if err != nil {
+				return 0, err
+			}
+
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(config.Dwell):
+			}
+
+			active, err := c.activity(ctx, config)
+			if err != nil {
+				return 0, err
+			}
+			if active {
+				return frequency, nil
+			}
+		}
+		if !config.Loop {
+			return 0, ErrNoActivity
+		}
+	}
+}
+
+func (c *Conn) activity(ctx context.Context, config ScanConfig) (bool, error) {
+	switch config.Detection {
+	case DetectStrength:
+		strength, err := c.Level(ctx, LevelStrength)
+		if err != nil {
+			return false, err
+		}
+		return strength >= config.Threshold, nil
+	default:
+		return c.DCD(ctx)
+	}
+}
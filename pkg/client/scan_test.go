@@ -0,0 +1,22 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanConfigSteps(t *testing.T) {
+	config := ScanConfig{From: 145500000, To: 145600000, Step: 12500}
+
+	assert.NoError(t, config.validate())
+	assert.Equal(t, 9, config.steps())
+	assert.Equal(t, Frequency(145500000), config.frequency(0))
+	assert.Equal(t, Frequency(145600000), config.frequency(8))
+}
+
+func TestScanConfigValidate(t *testing.T) {
+	assert.Error(t, ScanConfig{From: 145500000, To: 145600000}.validate(), "no step")
+	assert.Error(t, ScanConfig{From: 145600000, To: 145500000, Step: 12500}.validate(), "reverse range")
+	assert.NoError(t, ScanConfig{From: 145500000, To: 145500000, Step: 12500}.validate(), "single frequency")
+}
@@ -0,0 +1,661 @@
+// Package config provides the configuration file of rigproxy.
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ftl/rigproxy/pkg/bandplans"
+	"github.com/ftl/rigproxy/pkg/protocol"
+	"github.com/ftl/rigproxy/pkg/proxy"
+)
+
+// UpstreamType defines which kind of Hamlib server an upstream is.
+type UpstreamType string
+
+const (
+	RigUpstream     UpstreamType = "rig"
+	RotatorUpstream UpstreamType = "rotator"
+)
+
+// Config contains the complete configuration of rigproxy.
+type Config struct {
+	Upstreams []Upstream    `yaml:"upstreams"`
+	Cache     Cache         `yaml:"cache"`
+	ACL       []ACLRule     `yaml:"acl"`
+	Auth      Auth          `yaml:"auth"`
+	Admin     Admin         `yaml:"admin"`
+	Logging   Logging       `yaml:"logging"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Retry     time.Duration `yaml:"retry"`
+}
+
+// The prefixes of listening addresses that are not TCP addresses.
+const (
+	UnixPrefix    = "unix:"
+	SystemdPrefix = "systemd:"
+)
+
+// Upstream is a rigctld or rotctld server that is proxied on the given listening addresses. A listening address is
+// either a TCP address, a Unix domain socket (unix:/path/to/socket) or a socket passed by systemd socket activation
+// (systemd:<FileDescriptorName>). SocketMode defines the file permissions of the Unix domain sockets in octal
+// notation, e.g. 0660. If TLS is set, the clients must connect through TLS. If DestinationTLS is set, the connection
+// to the destination server uses TLS. Password is sent to the destination server with the password command after
+// connecting. Transverters map the frequencies of the rig to the on-air frequencies that the clients see. TXGuard
+// refuses transmissions that are not allowed for the configured license class.
+type Upstream struct {
+	Name           string        `yaml:"name"`
+	Type           UpstreamType  `yaml:"type"`
+	Destination    string        `yaml:"destination"`
+	DestinationTLS *ClientTLS    `yaml:"destination_tls"`
+	Password       string        `yaml:"password"`
+	Listen         []string      `yaml:"listen"`
+	SocketMode     string        `yaml:"socket_mode"`
+	TLS            *ServerTLS    `yaml:"tls"`
+	FanOut         []string      `yaml:"fan_out"`
+	Transverters   []Transverter `yaml:"transverters"`
+	TXGuard        *TXGuard      `yaml:"tx_guard"`
+}
+
+// TXGuard defines the license class that is used to check the transmissions against the bandplan of the IARU region
+// (1, 2 or 3, region 1 if not set). Bands contains the allocated bands (e.g. 80m or 20m), all bands of the bandplan
+// are allowed if Bands is empty. Ranges contains additional allocations that are not covered by the bandplan, e.g.
+// VHF and UHF bands. With ModeSegments, transmissions must also respect the mode segments of the bandplan.
+type TXGuard struct {
+	Region       int              `yaml:"region"`
+	LicenseClass string           `yaml:"license_class"`
+	Bands        []string         `yaml:"bands"`
+	Ranges       []FrequencyRange `yaml:"ranges"`
+	ModeSegments bool             `yaml:"mode_segments"`
+}
+
+// FrequencyRange is a range of frequencies in Hz.
+type FrequencyRange struct {
+	From int `yaml:"from"`
+	To   int `yaml:"to"`
+}
+
+// Transverter maps the frequency range From-To of the rig (in Hz) to the on-air frequency range by adding Offset
+// (in Hz). With BandSwitch, the mapping is only active while it is selected, either by tuning into its on-air range
+// or through the admin interface. Without band switch, the mapping is always active.
+type Transverter struct {
+	Name       string `yaml:"name"`
+	From       int    `yaml:"from"`
+	To         int    `yaml:"to"`
+	Offset     int    `yaml:"offset"`
+	BandSwitch bool   `yaml:"band_switch"`
+}
+
+// ServerTLS defines the certificate of a TLS listener. If ClientCA is set, the clients must present a certificate
+// signed by this CA.
+type ServerTLS struct {
+	Cert     string `yaml:"cert"`
+	Key      string `yaml:"key"`
+	ClientCA string `yaml:"client_ca"`
+}
+
+// ClientTLS defines how the TLS connection to a destination server is verified. If CA is empty, the system's
+// certificate pool is used. Cert and Key define an optional client certificate.
+type ClientTLS struct {
+	CA                 string `yaml:"ca"`
+	Cert               string `yaml:"cert"`
+	Key                string `yaml:"key"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// Cache defines the lifetime of the cached responses. Commands maps command keys (e.g. get_freq or get_level_STRENGTH)
+// to individual lifetimes, a negative lifetime disables caching for that command.
+type Cache struct {
+	Lifetime time.Duration            `yaml:"lifetime"`
+	Commands map[string]time.Duration `yaml:"commands"`
+}
+
+// ACLRule grants or denies access for clients from the given network, either in CIDR notation or as single IP address.
+type ACLRule struct {
+	Network  string `yaml:"network"`
+	ReadOnly bool   `yaml:"read_only"`
+	Deny     bool   `yaml:"deny"`
+}
+
+// Auth defines the tokens that clients must send with the password command before any other command is accepted.
+// Without tokens, no authentication is required.
+type Auth struct {
+	Tokens []string `yaml:"tokens"`
+}
+
+// Admin defines the listening address of the local admin interface, either a Unix domain socket, a socket passed by
+// systemd or a TCP address on the loopback interface. The admin interface is disabled if Listen is empty.
+type Admin struct {
+	Listen     string `yaml:"listen"`
+	SocketMode string `yaml:"socket_mode"`
+}
+
+// LogFormat defines the format of the log output.
+type LogFormat string
+
+const (
+	// DefaultFormat is the output format of the standard log package.
+	DefaultFormat LogFormat = ""
+	TextFormat    LogFormat = "text"
+	JSONFormat    LogFormat = "json"
+)
+
+// Logging defines where the log output goes, its format and if the communication with the upstreams is traced.
+type Logging struct {
+	Trace  bool      `yaml:"trace"`
+	File   string    `yaml:"file"`
+	Format LogFormat `yaml:"format"`
+}
+
+// Default returns the default configuration, with one rig upstream on localhost:4534 that is proxied on :4532.
+func Default() Config {
+	return Config{
+		Upstreams: []Upstream{
+			{Name: "rig", Type: RigUpstream, Destination: "localhost:4534", Listen: []string{":4532"}},
+		},
+		Cache: Cache{
+			Lifetime: 200 * time.Millisecond,
+		},
+		Timeout: 10 * time.Second,
+		Retry:   10 * time.Second,
+	}
+}
+
+// Load reads and validates the configuration file with the given name.
+func Load(filename string) (Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Config{}, err
+	}
+	result, err := Parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", filename, err)
+	}
+	return result, nil
+}
+
+// Parse parses and validates the given YAML configuration. Values that are not set in the configuration keep their
+// default values, except for the upstreams, which replace the default upstream if set.
+func Parse(data []byte) (Config, error) {
+	result := Default()
+	result.Upstreams = nil
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, err
+	}
+	if len(result.Upstreams) == 0 {
+		result.Upstreams = Default().Upstreams
+	}
+
+	err = result.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+	return result, nil
+}
+
+// Validate checks the configuration and reports all problems that were found.
+func (c Config) Validate() error {
+	var errs []error
+
+	names := make(map[string]bool, len(c.Upstreams))
+	listeners := make(map[string]string)
+	for i, upstream := range c.Upstreams {
+		name := upstream.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		} else if names[name] {
+			errs = append(errs, fmt.Errorf("upstream %s: duplicate name", name))
+		}
+		names[name] = true
+
+		switch upstream.Type {
+		case RigUpstream, RotatorUpstream:
+		default:
+			errs = append(errs, fmt.Errorf("upstream %s: invalid type %q, must be %q or %q", name, upstream.Type, RigUpstream, RotatorUpstream))
+		}
+		if upstream.Destination == "" {
+			errs = append(errs, fmt.Errorf("upstream %s: missing destination", name))
+		}
+		if len(upstream.Listen) == 0 {
+			errs = append(errs, fmt.Errorf("upstream %s: missing listening address", name))
+		}
+		for _, listen := range upstream.Listen {
+			if err := validateListenAddress(listen); err != nil {
+				errs = append(errs, fmt.Errorf("upstream %s: %w", name, err))
+			}
+			if other, ok := listeners[listen]; ok {
+				errs = append(errs, fmt.Errorf("upstream %s: listening address %s is already used by upstream %s", name, listen, other))
+			}
+			listeners[listen] = name
+		}
+		if _, err := upstream.FileMode(); err != nil {
+			errs = append(errs, fmt.Errorf("upstream %s: %w", name, err))
+		}
+		if strings.ContainsAny(upstream.Password, " \t\r\n") {
+			errs = append(errs, fmt.Errorf("upstream %s: password contains whitespace", name))
+		}
+		if err := upstream.TLS.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("upstream %s: tls: %w", name, err))
+		}
+		if err := upstream.DestinationTLS.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("upstream %s: destination_tls: %w", name, err))
+		}
+		if len(upstream.FanOut) > 0 && upstream.Type != RigUpstream {
+			errs = append(errs, fmt.Errorf("upstream %s: fan-out is only supported for rig upstreams", name))
+		}
+		for _, key := range upstream.FanOut {
+			if !isRigInfoKey(protocol.CommandKey(key)) {
+				errs = append(errs, fmt.Errorf("upstream %s: %s cannot be answered by the fan-out", name, key))
+			}
+		}
+		if len(upstream.Transverters) > 0 && upstream.Type != RigUpstream {
+			errs = append(errs, fmt.Errorf("upstream %s: transverters are only supported for rig upstreams", name))
+		}
+		for _, err := range validateTransverters(upstream.Transverters) {
+			errs = append(errs, fmt.Errorf("upstream %s: %w", name, err))
+		}
+		if upstream.TXGuard != nil && upstream.Type != RigUpstream {
+			errs = append(errs, fmt.Errorf("upstream %s: the tx guard is only supported for rig upstreams", name))
+		}
+		for _, err := range upstream.TXGuard.validate() {
+			errs = append(errs, fmt.Errorf("upstream %s: tx_guard: %w", name, err))
+		}
+	}
+
+	if c.Cache.Lifetime < 0 {
+		errs = append(errs, fmt.Errorf("cache: negative lifetime %v", c.Cache.Lifetime))
+	}
+	for key := range c.Cache.Commands {
+		if !isCacheableKey(key) {
+			errs = append(errs, fmt.Errorf("cache: %s is not a cacheable command", key))
+		}
+	}
+
+	for i, rule := range c.ACL {
+		if _, err := rule.proxyRule(); err != nil {
+			errs = append(errs, fmt.Errorf("acl rule #%d: %w", i+1, err))
+		}
+	}
+
+	for i, token := range c.Auth.Tokens {
+		if token == "" {
+			errs = append(errs, fmt.Errorf("auth: token #%d is empty", i+1))
+		} else if strings.ContainsAny(token, " \t\r\n") {
+			errs = append(errs, fmt.Errorf("auth: token #%d contains whitespace", i+1))
+		}
+	}
+
+	switch c.Logging.Format {
+	case DefaultFormat, TextFormat, JSONFormat:
+	default:
+		errs = append(errs, fmt.Errorf("logging: invalid format %q, must be %q or %q", c.Logging.Format, TextFormat, JSONFormat))
+	}
+
+	if c.Admin.Listen != "" {
+		if err := validateListenAddress(c.Admin.Listen); err != nil {
+			errs = append(errs, fmt.Errorf("admin: %w", err))
+		} else if !isLocalAddress(c.Admin.Listen) {
+			errs = append(errs, fmt.Errorf("admin: %s is not a local address", c.Admin.Listen))
+		}
+		if other, ok := listeners[c.Admin.Listen]; ok {
+			errs = append(errs, fmt.Errorf("admin: listening address %s is already used by upstream %s", c.Admin.Listen, other))
+		}
+	}
+	if _, err := parseFileMode(c.Admin.SocketMode); err != nil {
+		errs = append(errs, fmt.Errorf("admin: %w", err))
+	}
+
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("timeout must be positive"))
+	}
+	if c.Retry <= 0 {
+		errs = append(errs, fmt.Errorf("retry must be positive"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RequiresRestart indicates if the changes between this and the given configuration can only be applied by a
+// restart of rigproxy. The cache lifetimes, the ACL, the authentication tokens, the log file and the trace setting
+// can be applied to the running instance, a changed trace setting applies to all running client sessions.
+func (c Config) RequiresRestart(other Config) bool {
+	return !reflect.DeepEqual(c.Upstreams, other.Upstreams) || c.Admin != other.Admin || c.Logging.Format != other.Logging.Format || c.Timeout != other.Timeout || c.Retry != other.Retry
+}
+
+// CacheLifetimes returns the individual cache lifetimes of the configured commands.
+func (c Config) CacheLifetimes() map[protocol.CommandKey]time.Duration {
+	result := make(map[protocol.CommandKey]time.Duration, len(c.Cache.Commands))
+	for key, lifetime := range c.Cache.Commands {
+		result[protocol.CommandKey(key)] = lifetime
+	}
+	return result
+}
+
+// ProxyACL returns the ACL that restricts the access of clients to the proxies.
+func (c Config) ProxyACL() (*proxy.ACL, error) {
+	rules := make([]proxy.ACLRule, len(c.ACL))
+	for i, rule := range c.ACL {
+		proxyRule, err := rule.proxyRule()
+		if err != nil {
+			return nil, err
+		}
+		rules[i] = proxyRule
+	}
+	return proxy.NewACL(rules...), nil
+}
+
+// ProxyTransverter returns the transverter that maps the frequencies of this upstream. It returns nil if no
+// transverter is configured.
+func (u Upstream) ProxyTransverter() *proxy.Transverter {
+	if len(u.Transverters) == 0 {
+		return nil
+	}
+	mappings := make([]proxy.FrequencyMapping, len(u.Transverters))
+	for i, transverter := range u.Transverters {
+		mappings[i] = proxy.FrequencyMapping{
+			Name:       transverter.Name,
+			From:       transverter.From,
+			To:         transverter.To,
+			Offset:     transverter.Offset,
+			BandSwitch: transverter.BandSwitch,
+		}
+	}
+	return proxy.NewTransverter(mappings...)
+}
+
+// ProxyTXGuard returns the TXGuard that checks the transmissions on this upstream. It returns nil if no tx guard is
+// configured.
+func (u Upstream) ProxyTXGuard() *proxy.TXGuard {
+	if u.TXGuard == nil {
+		return nil
+	}
+	license := proxy.LicenseClass{
+		Name:         u.TXGuard.LicenseClass,
+		Bands:        make([]bandplan.BandName, len(u.TXGuard.Bands)),
+		Ranges:       make([]hamradio.FrequencyRange, len(u.TXGuard.Ranges)),
+		ModeSegments: u.TXGuard.ModeSegments,
+	}
+	for i, band := range u.TXGuard.Bands {
+		license.Bands[i] = bandplan.BandName(band)
+	}
+	for i, r := range u.TXGuard.Ranges {
+		license.Ranges[i] = hamradio.FrequencyRange{From: hamradio.Frequency(r.From), To: hamradio.Frequency(r.To)}
+	}
+	// the region is checked by Validate, without bandplan all transmissions outside of the ranges are refused
+	plan, _ := u.TXGuard.bandplan()
+	return proxy.NewTXGuard(plan, license)
+}
+
+// bandplan returns the bandplan of the configured IARU region.
+func (g *TXGuard) bandplan() (bandplan.Bandplan, error) {
+	region := g.Region
+	if region == 0 {
+		region = bandplans.DefaultRegion
+	}
+	return bandplans.ForRegion(region)
+}
+
+func (g *TXGuard) validate() []error {
+	if g == nil {
+		return nil
+	}
+	var errs []error
+	if g.LicenseClass == "" {
+		errs = append(errs, fmt.Errorf("missing license class"))
+	}
+	plan, err := g.bandplan()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, band := range g.Bands {
+		if _, ok := plan[bandplan.BandName(band)]; plan != nil && !ok {
+			errs = append(errs, fmt.Errorf("unknown band %q", band))
+		}
+	}
+	for i, r := range g.Ranges {
+		if r.From <= 0 || r.To <= r.From {
+			errs = append(errs, fmt.Errorf("range #%d: invalid frequency range %d-%d", i+1, r.From, r.To))
+		}
+	}
+	return errs
+}
+
+func validateTransverters(transverters []Transverter) []error {
+	var errs []error
+	names := make(map[string]bool, len(transverters))
+	for i, transverter := range transverters {
+		name := transverter.Name
+		if name == "" {
+			errs = append(errs, fmt.Errorf("transverter #%d: missing name", i+1))
+			name = fmt.Sprintf("#%d", i+1)
+		} else if names[name] {
+			errs = append(errs, fmt.Errorf("transverter %s: duplicate name", name))
+		}
+		names[name] = true
+
+		if transverter.From <= 0 || transverter.To <= transverter.From {
+			errs = append(errs, fmt.Errorf("transverter %s: invalid frequency range %d-%d", name, transverter.From, transverter.To))
+		}
+		if transverter.Offset == 0 || transverter.From+transverter.Offset <= 0 {
+			errs = append(errs, fmt.Errorf("transverter %s: invalid offset %d", name, transverter.Offset))
+		}
+
+		for _, other := range transverters[:i] {
+			if overlaps(transverter.From+transverter.Offset, transverter.To+transverter.Offset, other.From+other.Offset, other.To+other.Offset) {
+				errs = append(errs, fmt.Errorf("transverter %s: on-air frequency range overlaps with transverter %s", name, other.Name))
+			}
+			if (!transverter.BandSwitch || !other.BandSwitch) && overlaps(transverter.From, transverter.To, other.From, other.To) {
+				errs = append(errs, fmt.Errorf("transverter %s: frequency range overlaps with transverter %s, both need a band switch", name, other.Name))
+			}
+		}
+	}
+	return errs
+}
+
+func overlaps(from1, to1, from2, to2 int) bool {
+	return from1 <= to2 && from2 <= to1
+}
+
+func (r ACLRule) proxyRule() (proxy.ACLRule, error) {
+	if r.ReadOnly && r.Deny {
+		return proxy.ACLRule{}, fmt.Errorf("%s: read_only and deny are mutually exclusive", r.Network)
+	}
+	return proxy.ParseACLRule(r.Network, r.ReadOnly, r.Deny)
+}
+
+func (t *ServerTLS) validate() error {
+	if t == nil {
+		return nil
+	}
+	if t.Cert == "" || t.Key == "" {
+		return fmt.Errorf("cert and key are required")
+	}
+	return nil
+}
+
+// Config loads the certificates and returns the TLS configuration of a listener. It returns nil if TLS is not
+// configured.
+func (t *ServerTLS) Config() (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+	if err != nil {
+		return nil, err
+	}
+	result := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if t.ClientCA != "" {
+		pool, err := loadCertPool(t.ClientCA)
+		if err != nil {
+			return nil, err
+		}
+		result.ClientCAs = pool
+		result.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return result, nil
+}
+
+func (t *ClientTLS) validate() error {
+	if t == nil {
+		return nil
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return fmt.Errorf("cert and key are required for a client certificate")
+	}
+	return nil
+}
+
+// Config loads the certificates and returns the TLS configuration of the connection to a destination server. It
+// returns nil if TLS is not configured.
+func (t *ClientTLS) Config() (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	result := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if t.CA != "" {
+		pool, err := loadCertPool(t.CA)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = pool
+	}
+	if t.Cert != "" || t.Key != "" {
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+	return result, nil
+}
+
+func loadCertPool(filename string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	result := x509.NewCertPool()
+	if !result.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s contains no certificates", filename)
+	}
+	return result, nil
+}
+
+// FanOutKeys returns the command keys that are answered by the fan-out of this upstream.
+func (u Upstream) FanOutKeys() []protocol.CommandKey {
+	result := make([]protocol.CommandKey, len(u.FanOut))
+	for i, key := range u.FanOut {
+		result[i] = protocol.CommandKey(key)
+	}
+	return result
+}
+
+// FileMode returns the file permissions of the Unix domain sockets of this upstream. If no socket mode is configured,
+// FileMode returns 0 and the permissions are defined by the umask of the process.
+func (u Upstream) FileMode() (os.FileMode, error) {
+	return parseFileMode(u.SocketMode)
+}
+
+// FileMode returns the file permissions of the Unix domain socket of the admin interface. If no socket mode is
+// configured, FileMode returns 0 and the permissions are defined by the umask of the process.
+func (a Admin) FileMode() (os.FileMode, error) {
+	return parseFileMode(a.SocketMode)
+}
+
+func parseFileMode(socketMode string) (os.FileMode, error) {
+	if socketMode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q", socketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+func validateListenAddress(address string) error {
+	switch {
+	case address == UnixPrefix:
+		return fmt.Errorf("missing path of Unix domain socket %q", address)
+	case address == SystemdPrefix:
+		return fmt.Errorf("missing file descriptor name of systemd socket %q", address)
+	case strings.HasPrefix(address, UnixPrefix), strings.HasPrefix(address, SystemdPrefix):
+		return nil
+	}
+	_, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid listening address: %w", err)
+	}
+	return nil
+}
+
+func isLocalAddress(address string) bool {
+	if strings.HasPrefix(address, UnixPrefix) || strings.HasPrefix(address, SystemdPrefix) {
+		return true
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func isRigInfoKey(key protocol.CommandKey) bool {
+	for _, rigInfoKey := range proxy.RigInfoKeys {
+		if key == rigInfoKey {
+			return true
+		}
+	}
+	return false
+}
+
+func isCacheableKey(key string) bool {
+	if cmd, ok := protocol.LongCommands[key]; ok {
+		return cmd.Cacheable
+	}
+	if cmd, ok := protocol.RotatorLongCommands[key]; ok {
+		return cmd.Cacheable
+	}
+	for _, cmd := range protocol.Commands {
+		if cmd.Cacheable && cmd.HasSubCommand && strings.HasPrefix(key, cmd.Long+"_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+	"github.com/ftl/rigproxy/pkg/proxy"
+)
+
+const exampleConfig = `
+upstreams:
+  - name: ic7300
+    type: rig
+    destination: localhost:4534
+    listen: [":4532", "127.0.0.1:4632"]
+    fan_out: [get_freq, get_mode]
+  - name: rotator
+    type: rotator
+    destination: localhost:4535
+    listen: [":4533", "unix:/run/rigproxy/rotator.sock", "systemd:rotator"]
+    socket_mode: "0660"
+cache:
+  lifetime: 300ms
+  commands:
+    get_level_STRENGTH: 100ms
+    get_ptt: -1s
+acl:
+  - network: 192.168.1.13
+    deny: true
+  - network: 192.168.1.0/24
+    read_only: true
+  - network: 127.0.0.1
+admin:
+  listen: unix:/run/rigproxy/admin.sock
+  socket_mode: "0600"
+logging:
+  trace: true
+  file: /var/log/rigproxy.log
+  format: json
+timeout: 5s
+`
+
+func TestParse(t *testing.T) {
+	actual, err := Parse([]byte(exampleConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Upstream{
+		{Name: "ic7300", Type: RigUpstream, Destination: "localhost:4534", Listen: []string{":4532", "127.0.0.1:4632"}, FanOut: []string{"get_freq", "get_mode"}},
+		{Name: "rotator", Type: RotatorUpstream, Destination: "localhost:4535", Listen: []string{":4533", "unix:/run/rigproxy/rotator.sock", "systemd:rotator"}, SocketMode: "0660"},
+	}, actual.Upstreams)
+	assert.Equal(t, 300*time.Millisecond, actual.Cache.Lifetime)
+	assert.Equal(t, map[protocol.CommandKey]time.Duration{
+		"get_level_STRENGTH": 100 * time.Millisecond,
+		"get_ptt":            -1 * time.Second,
+	}, actual.CacheLifetimes())
+	mode, err := actual.Upstreams[1].FileMode()
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), mode)
+	assert.Len(t, actual.ACL, 3)
+	assert.Equal(t, Admin{Listen: "unix:/run/rigproxy/admin.sock", SocketMode: "0600"}, actual.Admin)
+	assert.Equal(t, Logging{Trace: true, File: "/var/log/rigproxy.log", Format: JSONFormat}, actual.Logging)
+	assert.Equal(t, 5*time.Second, actual.Timeout)
+	assert.Equal(t, Default().Retry, actual.Retry)
+}
+
+func TestParseEmptyUsesDefaults(t *testing.T) {
+	actual, err := Parse([]byte(""))
+	require.NoError(t, err)
+
+	assert.Equal(t, Default(), actual)
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config string
+	}{
+		{"unknown field", "lifetime: 1s"},
+		{"invalid duration", "timeout: soon"},
+		{"invalid type", "upstreams: [{type: amp, destination: localhost:4534, listen: [':4532']}]"},
+		{"missing destination", "upstreams: [{type: rig, listen: [':4532']}]"},
+		{"missing listen", "upstreams: [{type: rig, destination: localhost:4534}]"},
+		{"duplicate listen", "upstreams: [{name: a, type: rig, destination: localhost:4534, listen: [':4532']}, {name: b, type: rotator, destination: localhost:4535, listen: [':4532']}]"},
+		{"invalid listen", "upstreams: [{type: rig, destination: localhost:4534, listen: ['4532']}]"},
+		{"missing socket path", "upstreams: [{type: rig, destination: localhost:4534, listen: ['unix:']}]"},
+		{"missing systemd name", "upstreams: [{type: rig, destination: localhost:4534, listen: ['systemd:']}]"},
+		{"invalid socket mode", "upstreams: [{type: rig, destination: localhost:4534, listen: ['unix:/tmp/rig.sock'], socket_mode: rw}]"},
+		{"rotator fan-out", "upstreams: [{type: rotator, destination: localhost:4535, listen: [':4533'], fan_out: [get_freq]}]"},
+		{"invalid fan-out key", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], fan_out: [get_level]}]"},
+		{"not cacheable", "cache: {commands: {set_freq: 1s}}"},
+		{"missing tls key", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], tls: {cert: /tmp/cert.pem}}]"},
+		{"missing destination tls key", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], destination_tls: {cert: /tmp/cert.pem}}]"},
+		{"password with whitespace", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], password: 'my secret'}]"},
+		{"empty token", "auth: {tokens: ['']}"},
+		{"token with whitespace", "auth: {tokens: ['my secret']}"},
+		{"admin on all interfaces", "admin: {listen: ':4540'}"},
+		{"admin on lan", "admin: {listen: '192.168.1.2:4540'}"},
+		{"admin on upstream address", "admin: {listen: ':4532'}"},
+		{"invalid admin socket mode", "admin: {listen: 'unix:/run/rigproxy/admin.sock', socket_mode: '999'}"},
+		{"rotator transverter", "upstreams: [{type: rotator, destination: localhost:4535, listen: [':4533'], transverters: [{name: 2m, from: 28000000, to: 30000000, offset: 116000000}]}]"},
+		{"transverter without name", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], transverters: [{from: 28000000, to: 30000000, offset: 116000000}]}]"},
+		{"invalid transverter range", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], transverters: [{name: 2m, from: 30000000, to: 28000000, offset: 116000000}]}]"},
+		{"missing transverter offset", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], transverters: [{name: 2m, from: 28000000, to: 30000000}]}]"},
+		{"overlapping on-air ranges", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], transverters: [{name: 2m, from: 28000000, to: 30000000, offset: 116000000, band_switch: true}, {name: 2m-alt, from: 50000000, to: 52000000, offset: 94000000, band_switch: true}]}]"},
+		{"overlapping ranges without band switch", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], transverters: [{name: 2m, from: 28000000, to: 30000000, offset: 116000000}, {name: 4m, from: 28000000, to: 28500000, offset: 42000000, band_switch: true}]}]"},
+		{"rotator tx guard", "upstreams: [{type: rotator, destination: localhost:4535, listen: [':4533'], tx_guard: {license_class: A}}]"},
+		{"tx guard without license class", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], tx_guard: {bands: [20m]}}]"},
+		{"tx guard with unknown band", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], tx_guard: {license_class: A, bands: [11m]}}]"},
+		{"tx guard with unknown region", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], tx_guard: {region: 4, license_class: A}}]"},
+		{"tx guard with invalid range", "upstreams: [{type: rig, destination: localhost:4534, listen: [':4532'], tx_guard: {license_class: A, ranges: [{from: 146000000, to: 144000000}]}}]"},
+		{"invalid log format", "logging: {format: xml}"},
+		{"invalid network", "acl: [{network: 192.168.1}]"},
+		{"read-only and deny", "acl: [{network: 192.168.1.0/24, read_only: true, deny: true}]"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := Parse([]byte(tC.config))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRequiresRestart(t *testing.T) {
+	current := Default()
+
+	changedCache := Default()
+	changedCache.Cache.Lifetime = time.Second
+	changedCache.ACL = []ACLRule{{Network: "127.0.0.1"}}
+	changedCache.Logging.Trace = true
+	assert.False(t, current.RequiresRestart(changedCache))
+
+	changedUpstream := Default()
+	changedUpstream.Upstreams[0].Listen = []string{":4632"}
+	assert.True(t, current.RequiresRestart(changedUpstream))
+
+	changedAdmin := Default()
+	changedAdmin.Admin.Listen = "localhost:4540"
+	assert.True(t, current.RequiresRestart(changedAdmin))
+
+	changedFormat := Default()
+	changedFormat.Logging.Format = JSONFormat
+	assert.True(t, current.RequiresRestart(changedFormat))
+
+	changedTimeout := Default()
+	changedTimeout.Timeout = time.Second
+	assert.True(t, current.RequiresRestart(changedTimeout))
+}
+
+func TestProxyACL(t *testing.T) {
+	config, err := Parse([]byte(exampleConfig))
+	require.NoError(t, err)
+
+	acl, err := config.ProxyACL()
+	require.NoError(t, err)
+
+	setFreq := protocol.Request{Command: protocol.LongCommand("set_freq")}
+	assert.NoError(t, acl.Check(&net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, setFreq))
+	assert.ErrorIs(t, acl.Check(&net.TCPAddr{IP: net.ParseIP("192.168.1.10")}, setFreq), protocol.ErrSecurityError)
+}
+
+func TestProxyTransverter(t *testing.T) {
+	config, err := Parse([]byte(`
+upstreams:
+  - name: ic7300
+    type: rig
+    destination: localhost:4534
+    listen: [":4532"]
+    transverters:
+      - name: 2m
+        from: 28000000
+        to: 30000000
+        offset: 116000000
+        band_switch: true
+      - name: 4m
+        from: 28000000
+        to: 28500000
+        offset: 42000000
+        band_switch: true
+`))
+	require.NoError(t, err)
+
+	transverter := config.Upstreams[0].ProxyTransverter()
+	require.NotNil(t, transverter)
+	assert.Equal(t, []proxy.FrequencyMapping{
+		{Name: "2m", From: 28000000, To: 30000000, Offset: 116000000, BandSwitch: true},
+		{Name: "4m", From: 28000000, To: 28500000, Offset: 42000000, BandSwitch: true},
+	}, transverter.Mappings())
+	assert.Nil(t, Default().Upstreams[0].ProxyTransverter())
+}
+
+func TestProxyTXGuard(t *testing.T) {
+	config, err := Parse([]byte(`
+upstreams:
+  - name: ic7300
+    type: rig
+    destination: localhost:4534
+    listen: [":4532"]
+    tx_guard:
+      license_class: E
+      bands: [80m, 20m]
+      ranges: [{from: 144000000, to: 146000000}]
+      mode_segments: true
+`))
+	require.NoError(t, err)
+
+	guard := config.Upstreams[0].ProxyTXGuard()
+	require.NotNil(t, guard)
+	assert.NoError(t, guard.Check(14074000, bandplan.ModeDigital))
+	assert.NoError(t, guard.Check(144300000, bandplan.ModePhone))
+	assert.Error(t, guard.Check(7074000, bandplan.ModeDigital))
+	assert.Error(t, guard.Check(14025000, bandplan.ModePhone))
+	assert.Nil(t, Default().Upstreams[0].ProxyTXGuard())
+}
+
+func TestProxyTXGuardUsesRegionalBandplan(t *testing.T) {
+	parseGuard := func(region int) *proxy.TXGuard {
+		config, err := Parse([]byte(fmt.Sprintf(`
+upstreams:
+  - type: rig
+    destination: localhost:4534
+    listen: [":4532"]
+    tx_guard:
+      region: %d
+      license_class: A
+      mode_segments: true
+`, region)))
+		require.NoError(t, err)
+		return config.Upstreams[0].ProxyTXGuard()
+	}
+
+	assert.Error(t, parseGuard(0).Check(7250000, bandplan.ModePhone), "region 1 by default")
+	assert.Error(t, parseGuard(1).Check(7250000, bandplan.ModePhone))
+	assert.NoError(t, parseGuard(2).Check(7250000, bandplan.ModePhone))
+	assert.NoError(t, parseGuard(2).Check(3950000, bandplan.ModePhone))
+	assert.Error(t, parseGuard(3).Check(3950000, bandplan.ModePhone))
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir)
+
+	cfg, err := Parse([]byte(fmt.Sprintf(`
+upstreams:
+  - name: remote
+    type: rig
+    destination: remote.example.com:4534
+    destination_tls:
+      ca: %[1]s
+      server_name: remote.example.com
+    password: upstream-secret
+    listen: [":4532"]
+    tls:
+      cert: %[1]s
+      key: %[2]s
+      client_ca: %[1]s
+auth:
+  tokens: [secret]
+`, certFile, keyFile)))
+	require.NoError(t, err)
+
+	serverTLS, err := cfg.Upstreams[0].TLS.Config()
+	require.NoError(t, err)
+	assert.Len(t, serverTLS.Certificates, 1)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, serverTLS.ClientAuth)
+
+	clientTLS, err := cfg.Upstreams[0].DestinationTLS.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "remote.example.com", clientTLS.ServerName)
+	assert.NotNil(t, clientTLS.RootCAs)
+	assert.Empty(t, clientTLS.Certificates)
+
+	assert.Equal(t, "upstream-secret", cfg.Upstreams[0].Password)
+	assert.Equal(t, []string{"secret"}, cfg.Auth.Tokens)
+}
+
+func TestTLSConfigNotSet(t *testing.T) {
+	var serverTLS *ServerTLS
+	var clientTLS *ClientTLS
+
+	actualServer, err := serverTLS.Config()
+	assert.NoError(t, err)
+	assert.Nil(t, actualServer)
+	actualClient, err := clientTLS.Config()
+	assert.NoError(t, err)
+	assert.Nil(t, actualClient)
+}
+
+func TestTLSFilesAreLoadedWhenApplied(t *testing.T) {
+	cfg, err := Parse([]byte(`
+upstreams:
+  - type: rig
+    destination: localhost:4534
+    destination_tls: {ca: /nonexistent/ca.pem}
+    listen: [":4532"]
+    tls: {cert: /nonexistent/cert.pem, key: /nonexistent/key.pem}
+`))
+	require.NoError(t, err, "validation does not access the file system")
+
+	_, err = cfg.Upstreams[0].TLS.Config()
+	assert.Error(t, err)
+	_, err = cfg.Upstreams[0].DestinationTLS.Config()
+	assert.Error(t, err)
+}
+
+func writeSelfSignedCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "remote.example.com"},
+		DNSNames:              []string{"remote.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
@@ -0,0 +1,55 @@
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EncodeCAT encodes the given raw CAT command as argument for send_cmd and send_cmd_rx. Commands that consist only of
+// printable characters are sent as text, like the commands of Kenwood or Yaesu radios. All other commands are encoded
+// byte by byte as \0xNN escapes, which rigctld sends to the radio in binary form.
+func EncodeCAT(data []byte) string {
+	if isPrintableCAT(data) {
+		return string(data)
+	}
+	var result strings.Builder
+	for _, b := range data {
+		fmt.Fprintf(&result, "\\0x%02X", b)
+	}
+	return result.String()
+}
+
+func isPrintableCAT(data []byte) bool {
+	if len(data) == 0 || data[0] == '\\' {
+		return false
+	}
+	for _, b := range data {
+		if b <= ' ' || b > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// DecodeCAT decodes the given CAT string into raw bytes. The string may contain \0xNN escapes, all other characters
+// are taken literally.
+func DecodeCAT(s string) ([]byte, error) {
+	result := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			result = append(result, s[i])
+			continue
+		}
+		if i+5 > len(s) || s[i+1:i+3] != "0x" {
+			return nil, fmt.Errorf("%w: invalid escape sequence at position %d in %q", ErrInvalidParameter, i, s)
+		}
+		b, err := strconv.ParseUint(s[i+3:i+5], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid escape sequence at position %d in %q", ErrInvalidParameter, i, s)
+		}
+		result = append(result, byte(b))
+		i += 4
+	}
+	return result, nil
+}
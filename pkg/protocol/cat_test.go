@@ -0,0 +1,57 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCAT(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    []byte
+		expected string
+	}{
+		{"text", []byte("FA;"), "FA;"},
+		{"binary", []byte{0xFE, 0xFE, 0x94, 0xE0, 0x03, 0xFD}, "\\0xFE\\0xFE\\0x94\\0xE0\\0x03\\0xFD"},
+		{"text with space", []byte("FA 1;"), "\\0x46\\0x41\\0x20\\0x31\\0x3B"},
+		{"leading backslash", []byte("\\0x"), "\\0x5C\\0x30\\0x78"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual := EncodeCAT(tC.value)
+			assert.Equal(t, tC.expected, actual)
+
+			decoded, err := DecodeCAT(actual)
+			assert.NoError(t, err)
+			assert.Equal(t, tC.value, decoded)
+		})
+	}
+}
+
+func TestDecodeCAT(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected []byte
+		valid    bool
+	}{
+		{"text", "FA00014074000;", []byte("FA00014074000;"), true},
+		{"escapes", "\\0xfe\\0xFE", []byte{0xFE, 0xFE}, true},
+		{"mixed", "FA\\0x3B", []byte("FA;"), true},
+		{"incomplete escape", "\\0xF", nil, false},
+		{"invalid escape", "\\1x20", nil, false},
+		{"invalid hex", "\\0xZZ", nil, false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := DecodeCAT(tC.value)
+			if tC.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tC.expected, actual)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -311,20 +311,28 @@ var (
 			SupportsExtendedMode: true,
 		},
 		{
-			Short: 'g',
-			Long:  "scan",
-			Args:  2,
+			Short:                'g',
+			Long:                 "scan",
+			Args:                 2,
+			InvalidatesCommand:   "get_freq",
+			SupportsExtendedMode: true,
 		},
 		{
-			Short:              'H',
-			Long:               "set_channel",
-			Args:               1,
-			InvalidatesCommand: "get_channel",
+			Short:                'H',
+			Long:                 "set_channel",
+			Args:                 1,
+			ArgsInLine:           true,
+			InvalidatesCommand:   "get_channel",
+			HasSubCommand:        true,
+			SupportsExtendedMode: true,
 		},
 		{
-			Short:     'h',
-			Long:      "get_channel",
-			Cacheable: true,
+			Short:                'h',
+			Long:                 "get_channel",
+			Args:                 2,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 		{
 			Short:                'A',
@@ -342,13 +350,14 @@ var (
 		{
 			Short:                'Y',
 			Long:                 "set_ant",
-			InvalidatesCommand:   "get_ant",
+			Args:                 2,
 			SupportsExtendedMode: true,
 		},
 		{
 			Short:                'y',
 			Long:                 "get_ant",
-			Cacheable:            true,
+			Args:                 1,
+			HasSubCommand:        true,
 			SupportsExtendedMode: true,
 		},
 		{
@@ -409,14 +418,18 @@ var (
 			Long:  "wait_morse",
 		},
 		{
-			Short: 'w',
-			Long:  "send_cmd",
-			Args:  2,
+			Short:          'w',
+			Long:           "send_cmd",
+			Args:           1,
+			ArgsInLine:     true,
+			InvalidatesAll: true,
 		},
 		{
-			Short: 'W',
-			Long:  "send_cmd_rx",
-			Args:  1,
+			Short:          'W',
+			Long:           "send_cmd_rx",
+			Args:           2,
+			TerminatorArg:  true,
+			InvalidatesAll: true,
 		},
 		{
 			Short:     '_',
@@ -482,6 +495,11 @@ var (
 			Args:                 1,
 			SupportsExtendedMode: true,
 		},
+		{
+			Short: 0x98,
+			Long:  "password",
+			Args:  1,
+		},
 		{
 			Short: 0x97,
 			Long:  "uplink",
@@ -499,28 +517,57 @@ var (
 			Cacheable: true,
 		},
 		{
-			Short: 0xf3,
-			Long:  "get_vfo_info",
-			Args:  1,
+			Short:                0xf3,
+			Long:                 "get_vfo_info",
+			Args:                 1,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 		{
-			Short: 0xf4,
-			Long:  "get_vfo_list",
+			Short:                0xf4,
+			Long:                 "get_vfo_list",
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 		{
-			Short: 0xf5,
-			Long:  "get_rig_info",
+			Short:                0xf5,
+			Long:                 "get_rig_info",
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 		{
-			Short: 0xf6,
-			Long:  "get_modes",
+			Short:                0xf6,
+			Long:                 "get_modes",
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 		{
-			Short: 0xf7,
-			Long:  "get_mode_bandwidths",
-			Args:  1,
+			Short:                0xf7,
+			Long:                 "get_mode_bandwidths",
+			Args:                 1,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
 		},
 	}
+
+	// SideEffects lists the responses that are invalidated by a command in addition to its InvalidatesCommand,
+	// because the rig changes them as a side effect of the command.
+	SideEffects = map[string][]CommandKey{
+		"set_freq":            {"get_dcd", "get_level_STRENGTH", "get_level_SWR", "get_rig_info"},
+		"set_mode":            {"get_rig_info"},
+		"set_vfo":             {"get_rig_info"},
+		"set_ptt":             {"get_rig_info"},
+		"set_split_vfo":       {"get_rig_info"},
+		"set_split_freq":      {"get_rig_info"},
+		"set_split_mode":      {"get_rig_info"},
+		"set_split_freq_mode": {"get_split_freq", "get_split_mode", "get_rig_info"},
+		"vfo_op":              {"get_mode", "get_vfo", "get_split_vfo", "get_rig_info"},
+		"scan":                {"get_mode", "get_rig_info"},
+		"set_mem":             {"get_freq", "get_mode", "get_rig_info"},
+		"set_channel":         {"get_freq", "get_mode", "get_rig_info"},
+	}
 )
 
 func init() {
@@ -12,3 +12,10 @@ func TestInit(t *testing.T) {
 		assert.Equal(t, cmd, LongCommands[cmd.Long])
 	}
 }
+
+func TestRotatorInit(t *testing.T) {
+	for _, cmd := range RotatorCommands {
+		assert.Equal(t, cmd, RotatorShortCommands[cmd.Short])
+		assert.Equal(t, cmd, RotatorLongCommands[cmd.Long])
+	}
+}
@@ -0,0 +1,126 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// Error represents a Hamlib error result. Use errors.Is to compare an error with one of the Err* values,
+// and errors.As to access the error code.
+type Error struct {
+	Code    int
+	Message string
+	Command CommandKey
+}
+
+func (e Error) Error() string {
+	if e.Command == "" {
+		return fmt.Sprintf("hamlib error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("hamlib error %d on %s: %s", e.Code, e.Command, e.Message)
+}
+
+// Is indicates if the target is an Error with the same code. The command is not taken into account.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.Code == e.Code
+}
+
+// NewError returns the Error for the given Hamlib error code that occurred on the given command.
+// Positive codes, as reported by older Hamlib versions, are normalized to negative codes.
+func NewError(code int, command CommandKey) Error {
+	if code > 0 {
+		code = -code
+	}
+	message, ok := errorMessagesByCode[code]
+	if !ok {
+		message = "Unknown error"
+	}
+	return Error{Code: code, Message: message, Command: command}
+}
+
+// ResultError returns the error that corresponds to the given result of a response to the given command.
+// If the result indicates success, ResultError returns nil.
+func ResultError(command CommandKey, result string) error {
+	if result == "0" {
+		return nil
+	}
+	code, err := strconv.Atoi(result)
+	if err != nil {
+		return Error{Code: ErrProtocolError.Code, Message: fmt.Sprintf("invalid result %q", result), Command: command}
+	}
+	return NewError(code, command)
+}
+
+// ErrorCode returns the Hamlib error code that represents the given error. Errors that do not wrap an Error
+// are mapped to the closest Hamlib error code.
+func ErrorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var hamlibErr Error
+	if errors.As(err, &hamlibErr) {
+		return hamlibErr.Code
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrCommunicationTimedOut.Code
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, net.ErrClosed), errors.Is(err, ErrTransceiverClosed), errors.As(err, &netErr):
+		return ErrIOError.Code
+	default:
+		return ErrInternalHamlibError.Code
+	}
+}
+
+var errorMessagesByCode = map[int]string{
+	-1:  "Invalid parameter",
+	-2:  "Invalid configuration",
+	-3:  "Memory shortage",
+	-4:  "Feature not implemented",
+	-5:  "Communication timed out",
+	-6:  "IO error",
+	-7:  "Internal Hamlib error",
+	-8:  "Protocol error",
+	-9:  "Command rejected by the rig",
+	-10: "Command performed, but arg truncated, result not guaranteed",
+	-11: "Feature not available",
+	-12: "Target VFO unaccessible",
+	-13: "Communication bus error",
+	-14: "Communication bus collision",
+	-15: "NULL RIG handle or invalid pointer parameter",
+	-16: "Invalid VFO",
+	-17: "Argument out of domain of func",
+	-18: "Function deprecated",
+	-19: "Security error password not provided or crypto failure",
+	-20: "Rig is not powered on",
+}
+
+var (
+	ErrInvalidParameter          = NewError(-1, NoCommand)
+	ErrInvalidConfiguration      = NewError(-2, NoCommand)
+	ErrMemoryShortage            = NewError(-3, NoCommand)
+	ErrFeatureNotImplemented     = NewError(-4, NoCommand)
+	ErrCommunicationTimedOut     = NewError(-5, NoCommand)
+	ErrIOError                   = NewError(-6, NoCommand)
+	ErrInternalHamlibError       = NewError(-7, NoCommand)
+	ErrProtocolError             = NewError(-8, NoCommand)
+	ErrCommandRejectedByRig      = NewError(-9, NoCommand)
+	ErrArgTruncated              = NewError(-10, NoCommand)
+	ErrFeatureNotAvailable       = NewError(-11, NoCommand)
+	ErrTargetVFOUnaccessible     = NewError(-12, NoCommand)
+	ErrCommunicationBusError     = NewError(-13, NoCommand)
+	ErrCommunicationBusCollision = NewError(-14, NoCommand)
+	ErrNullRigHandle             = NewError(-15, NoCommand)
+	ErrInvalidVFO                = NewError(-16, NoCommand)
+	ErrArgumentOutOfDomain       = NewError(-17, NoCommand)
+	ErrFunctionDeprecated        = NewError(-18, NoCommand)
+	ErrSecurityError             = NewError(-19, NoCommand)
+	ErrRigNotPoweredOn           = NewError(-20, NoCommand)
+)
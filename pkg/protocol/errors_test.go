@@ -0,0 +1,56 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultError(t *testing.T) {
+	assert.NoError(t, ResultError("get_freq", "0"))
+
+	err := ResultError("get_freq", "-11")
+	assert.ErrorIs(t, err, ErrFeatureNotAvailable)
+	assert.NotErrorIs(t, err, ErrFeatureNotImplemented)
+
+	var hamlibErr Error
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &hamlibErr))
+	assert.Equal(t, -11, hamlibErr.Code)
+	assert.Equal(t, CommandKey("get_freq"), hamlibErr.Command)
+	assert.Equal(t, "Feature not available", hamlibErr.Message)
+}
+
+func TestResultErrorNormalizesPositiveCodes(t *testing.T) {
+	err := ResultError("set_freq", "11")
+	assert.ErrorIs(t, err, ErrFeatureNotAvailable)
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      error
+		expected int
+	}{
+		{"nil", nil, 0},
+		{"hamlib error", ErrInvalidVFO, -16},
+		{"wrapped hamlib error", fmt.Errorf("request failed: %w", NewError(-9, "set_ptt")), -9},
+		{"deadline", context.DeadlineExceeded, -5},
+		{"eof", io.EOF, -6},
+		{"closed transceiver", ErrTransceiverClosed, -6},
+		{"any other error", errors.New("fail"), -7},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.expected, ErrorCode(tC.err))
+		})
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	resp := ErrorResponse("set_freq", fmt.Errorf("wrapped: %w", ErrCommandRejectedByRig))
+	assert.Equal(t, "RPRT -9", resp.Format())
+}
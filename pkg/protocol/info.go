@@ -0,0 +1,253 @@
+package protocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RigInfo is the parsed response of get_rig_info.
+type RigInfo struct {
+	VFOs    []RigInfoVFO
+	Split   bool
+	SatMode bool
+	// PTT is only reported by newer Hamlib versions, HasPTT indicates if the response contained the PTT state.
+	PTT     bool
+	HasPTT  bool
+	Rig     string
+	App     string
+	Version string
+	Model   int
+	CRC     string
+}
+
+// RigInfoVFO describes the state of one VFO in the response of get_rig_info.
+type RigInfoVFO struct {
+	Name      string
+	Frequency int
+	Mode      string
+	Width     int
+	RX        bool
+	TX        bool
+}
+
+// VFO returns the state of the VFO with the given name.
+func (i RigInfo) VFO(name string) (RigInfoVFO, bool) {
+	for _, vfo := range i.VFOs {
+		if vfo.Name == name {
+			return vfo, true
+		}
+	}
+	return RigInfoVFO{}, false
+}
+
+// TXVFO returns the state of the VFO that is used for transmitting.
+func (i RigInfo) TXVFO() (RigInfoVFO, bool) {
+	for _, vfo := range i.VFOs {
+		if vfo.TX {
+			return vfo, true
+		}
+	}
+	return RigInfoVFO{}, false
+}
+
+// RXVFO returns the state of the VFO that is used for receiving.
+func (i RigInfo) RXVFO() (RigInfoVFO, bool) {
+	for _, vfo := range i.VFOs {
+		if vfo.RX {
+			return vfo, true
+		}
+	}
+	return RigInfoVFO{}, false
+}
+
+// ParseRigInfo parses the response of get_rig_info. Each line of the response contains space separated key=value
+// pairs, values that contain spaces continue until the next key.
+func ParseRigInfo(r Response) (RigInfo, error) {
+	var result RigInfo
+	for _, line := range r.Lines() {
+		values := parseKeyValues(line)
+		if len(values) == 0 {
+			continue
+		}
+
+		invalid := func(err error) (RigInfo, error) {
+			return RigInfo{}, fmt.Errorf("%w: invalid rig info %q: %v", ErrProtocolError, line, err)
+		}
+
+		if name, ok := values.get("VFO"); ok {
+			vfo := RigInfoVFO{Name: name, Mode: values["Mode"]}
+			var err error
+			vfo.Frequency, err = parseIntValue(values, "Freq")
+			if err != nil {
+				return invalid(err)
+			}
+			vfo.Width, err = parseIntValue(values, "Width")
+			if err != nil {
+				return invalid(err)
+			}
+			vfo.RX = values["RX"] == "1"
+			vfo.TX = values["TX"] == "1"
+			result.VFOs = append(result.VFOs, vfo)
+		}
+		for key, value := range values {
+			switch key {
+			case "Split":
+				result.Split = value == "1"
+			case "SatMode":
+				result.SatMode = value == "1"
+			case "PTT":
+				result.PTT = value != "0"
+				result.HasPTT = true
+			case "Rig":
+				result.Rig = value
+			case "App":
+				result.App = value
+			case "Version":
+				result.Version = value
+			case "Model":
+				model, err := strconv.Atoi(value)
+				if err != nil {
+					return invalid(err)
+				}
+				result.Model = model
+			case "CRC":
+				result.CRC = value
+			}
+		}
+	}
+	if len(result.VFOs) == 0 {
+		return RigInfo{}, fmt.Errorf("%w: no VFO in rig info", ErrProtocolError)
+	}
+	return result, nil
+}
+
+// VFOInfo is the parsed response of get_vfo_info.
+type VFOInfo struct {
+	Frequency int
+	Mode      string
+	Width     int
+	Split     bool
+	SatMode   bool
+}
+
+var vfoInfoKeys = []string{"Freq", "Mode", "Width", "Split", "SatMode"}
+
+// ParseVFOInfo parses the response of get_vfo_info. The values are either given as keyed lines in extended mode or
+// as plain lines in the order frequency, mode, width, split and sat mode.
+func ParseVFOInfo(r Response) (VFOInfo, error) {
+	values := make(keyValues)
+	for i, value := range r.Data {
+		if i < len(r.Keys) && r.Keys[i] != "" {
+			values[r.Keys[i]] = value
+		} else if i < len(vfoInfoKeys) {
+			values[vfoInfoKeys[i]] = value
+		}
+	}
+
+	var result VFOInfo
+	var err error
+	result.Frequency, err = parseIntValue(values, "Freq")
+	if err != nil {
+		return VFOInfo{}, fmt.Errorf("%w: invalid VFO info: %v", ErrProtocolError, err)
+	}
+	result.Width, err = parseIntValue(values, "Width")
+	if err != nil {
+		return VFOInfo{}, fmt.Errorf("%w: invalid VFO info: %v", ErrProtocolError, err)
+	}
+	result.Mode = values["Mode"]
+	result.Split = values["Split"] == "1"
+	result.SatMode = values["SatMode"] == "1"
+	return result, nil
+}
+
+// ParseVFOList parses the response of get_vfo_list.
+func ParseVFOList(r Response) []string {
+	return parseWordList(r)
+}
+
+// ParseModes parses the response of get_modes.
+func ParseModes(r Response) []string {
+	return parseWordList(r)
+}
+
+// ModeBandwidths is the parsed response of get_mode_bandwidths.
+type ModeBandwidths struct {
+	Mode   string
+	Normal int
+	Narrow int
+	Wide   int
+}
+
+// ParseModeBandwidths parses the response of get_mode_bandwidths.
+func ParseModeBandwidths(r Response) (ModeBandwidths, error) {
+	values := make(keyValues)
+	for _, line := range r.Lines() {
+		for key, value := range parseKeyValues(line) {
+			values[key] = strings.TrimSuffix(value, "Hz")
+		}
+	}
+	mode, ok := values.get("Mode")
+	if !ok {
+		return ModeBandwidths{}, fmt.Errorf("%w: no mode in bandwidths", ErrProtocolError)
+	}
+
+	result := ModeBandwidths{Mode: mode}
+	var err error
+	result.Normal, err = parseIntValue(values, "Normal")
+	if err == nil {
+		result.Narrow, err = parseIntValue(values, "Narrow")
+	}
+	if err == nil {
+		result.Wide, err = parseIntValue(values, "Wide")
+	}
+	if err != nil {
+		return ModeBandwidths{}, fmt.Errorf("%w: invalid bandwidths: %v", ErrProtocolError, err)
+	}
+	return result, nil
+}
+
+type keyValues map[string]string
+
+func (v keyValues) get(key string) (string, bool) {
+	value, ok := v[key]
+	return value, ok
+}
+
+// parseKeyValues parses a line of space separated key=value pairs.
+func parseKeyValues(line string) keyValues {
+	result := make(keyValues)
+	lastKey := ""
+	for _, field := range strings.Fields(line) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			if lastKey != "" {
+				result[lastKey] += " " + field
+			}
+			continue
+		}
+		result[key] = value
+		lastKey = key
+	}
+	return result
+}
+
+func parseIntValue(values keyValues, key string) (int, error) {
+	value, ok := values[key]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return int(result), nil
+}
+
+func parseWordList(r Response) []string {
+	var result []string
+	for _, value := range r.Data {
+		result = append(result, strings.Fields(value)...)
+	}
+	return result
+}
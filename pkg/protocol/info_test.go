@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRigInfo(t *testing.T) {
+	response := Response{
+		Data: []string{
+			"VFO=VFOA Freq=14074000 Mode=USB Width=3000 RX=1 TX=0",
+			"VFO=VFOB Freq=14076000 Mode=PKTUSB Width=2400 RX=0 TX=1",
+			"Split=1 SatMode=0",
+			"Rig=IC-7300",
+			"App=Hamlib",
+			"Version=20210506 1.0.0",
+			"Model=3073",
+			"CRC=0x1a2b3c4d",
+			"",
+		},
+		Result: "0",
+	}
+
+	actual, err := ParseRigInfo(response)
+	require.NoError(t, err)
+
+	assert.Equal(t, RigInfo{
+		VFOs: []RigInfoVFO{
+			{Name: "VFOA", Frequency: 14074000, Mode: "USB", Width: 3000, RX: true},
+			{Name: "VFOB", Frequency: 14076000, Mode: "PKTUSB", Width: 2400, TX: true},
+		},
+		Split:   true,
+		Rig:     "IC-7300",
+		App:     "Hamlib",
+		Version: "20210506 1.0.0",
+		Model:   3073,
+		CRC:     "0x1a2b3c4d",
+	}, actual)
+
+	tx, ok := actual.TXVFO()
+	assert.True(t, ok)
+	assert.Equal(t, "VFOB", tx.Name)
+}
+
+func TestParseRigInfoWithPTT(t *testing.T) {
+	actual, err := ParseRigInfo(Response{Data: []string{"VFO=Main Freq=7074000 Mode=LSB Width=2700 RX=1 TX=1", "Split=0 SatMode=0 PTT=1"}})
+	require.NoError(t, err)
+
+	assert.True(t, actual.HasPTT)
+	assert.True(t, actual.PTT)
+}
+
+func TestParseRigInfoWithoutVFO(t *testing.T) {
+	_, err := ParseRigInfo(Response{Data: []string{"Split=0 SatMode=0"}})
+	assert.Error(t, err)
+}
+
+func TestParseRigInfoWithInvalidFrequency(t *testing.T) {
+	_, err := ParseRigInfo(Response{Data: []string{"VFO=VFOA Freq=abc Mode=USB Width=3000 RX=1 TX=1 Model=3073"}})
+	assert.ErrorIs(t, err, ErrProtocolError)
+}
+
+func TestParseVFOInfo(t *testing.T) {
+	expected := VFOInfo{Frequency: 14074000, Mode: "USB", Width: 3000, Split: true}
+	testCases := []struct {
+		desc     string
+		response Response
+	}{
+		{"plain", Response{Data: []string{"14074000", "USB", "3000", "1", "0"}}},
+		{"extended", Response{Data: []string{"14074000", "USB", "3000", "1", "0"}, Keys: []string{"Freq", "Mode", "Width", "Split", "SatMode"}}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			actual, err := ParseVFOInfo(tC.response)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseWordLists(t *testing.T) {
+	assert.Equal(t, []string{"VFOA", "VFOB", "MEM"}, ParseVFOList(Response{Data: []string{"VFOA VFOB MEM "}}))
+	assert.Equal(t, []string{"AM", "CW", "USB"}, ParseModes(Response{Data: []string{"AM CW USB"}, Keys: []string{"Modes"}}))
+}
+
+func TestParseModeBandwidths(t *testing.T) {
+	response := Response{Data: []string{"Mode=USB", "Normal=2400Hz", "Narrow=1800Hz", "Wide=3000Hz"}}
+
+	actual, err := ParseModeBandwidths(response)
+	require.NoError(t, err)
+
+	assert.Equal(t, ModeBandwidths{Mode: "USB", Normal: 2400, Narrow: 1800, Wide: 3000}, actual)
+}
@@ -0,0 +1,16 @@
+package protocol
+
+import "github.com/ftl/hamradio/bandplan"
+
+// BandplanMode maps the given Hamlib mode to the mode type of the bandplan package. Unknown modes are treated as
+// digital modes.
+func BandplanMode(mode string) bandplan.Mode {
+	switch mode {
+	case "CW", "CWR":
+		return bandplan.ModeCW
+	case "USB", "LSB", "AM", "FM", "WFM", "AMS", "DSB":
+		return bandplan.ModePhone
+	default:
+		return bandplan.ModeDigital
+	}
+}
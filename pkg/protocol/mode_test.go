@@ -0,0 +1,28 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBandplanMode(t *testing.T) {
+	testCases := []struct {
+		mode     string
+		expected bandplan.Mode
+	}{
+		{"CW", bandplan.ModeCW},
+		{"CWR", bandplan.ModeCW},
+		{"USB", bandplan.ModePhone},
+		{"FM", bandplan.ModePhone},
+		{"PKTUSB", bandplan.ModeDigital},
+		{"RTTY", bandplan.ModeDigital},
+		{"unknown", bandplan.ModeDigital},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.mode, func(t *testing.T) {
+			assert.Equal(t, tC.expected, BandplanMode(tC.mode))
+		})
+	}
+}
@@ -15,14 +15,51 @@ type RequestReader interface {
 }
 
 func NewRequestReader(r io.Reader) RequestReader {
+	return newRequestReader(r, rigCommands)
+}
+
+func NewRotatorRequestReader(r io.Reader) RequestReader {
+	return newRequestReader(r, rotatorCommands)
+}
+
+func newRequestReader(r io.Reader, commands commandTable) RequestReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanLines)
 	return &requestReader{
-		scanner: bufio.NewScanner(r),
+		scanner:  scanner,
+		commands: commands,
 	}
 }
 
+// scanLines splits the input into lines like bufio.ScanLines, but keeps a trailing carriage return, since it may be
+// the terminator argument of send_cmd_rx.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+type commandTable struct {
+	short map[byte]Command
+	long  map[string]Command
+}
+
+var (
+	rigCommands     = commandTable{short: ShortCommands, long: LongCommands}
+	rotatorCommands = commandTable{short: RotatorShortCommands, long: RotatorLongCommands}
+)
+
 type requestReader struct {
 	scanner     *bufio.Scanner
 	currentLine *bytes.Buffer
+	commands    commandTable
 }
 
 func (r *requestReader) ReadRequest() (Request, error) {
@@ -35,7 +72,7 @@ func (r *requestReader) ReadRequest() (Request, error) {
 			r.currentLine = bytes.NewBufferString(line)
 		}
 
-		req, err := nextRequest(r.currentLine)
+		req, err := r.commands.nextRequest(r.currentLine)
 		if err == io.EOF {
 			continue
 		}
@@ -56,6 +93,10 @@ func (r *requestReader) nextLine() (string, error) {
 }
 
 func nextRequest(r io.Reader) (Request, error) {
+	return rigCommands.nextRequest(r)
+}
+
+func (t commandTable) nextRequest(r io.Reader) (Request, error) {
 	c := make([]byte, 1)
 	var cmd Command
 loop:
@@ -75,20 +116,20 @@ loop:
 				return Request{}, err
 			}
 		case '+':
-			req, err := nextRequest(r)
+			req, err := t.nextRequest(r)
 			if err == nil && req.Command.SupportsExtendedMode {
 				req.ExtendedSeparator = "\n"
 			}
 			return req, err
 		case ';', ',', '|':
-			req, err := nextRequest(r)
+			req, err := t.nextRequest(r)
 			if err == nil && req.Command.SupportsExtendedMode {
 				req.ExtendedSeparator = string(c[0])
 			}
 			return req, err
 		case '\\':
 			var err error
-			cmd, err = readLongCommand(r)
+			cmd, err = t.readLongCommand(r)
 			if err != nil {
 				return Request{}, err
 			}
@@ -98,7 +139,7 @@ loop:
 				continue
 			}
 			var ok bool
-			cmd, ok = ShortCommands[c[0]]
+			cmd, ok = t.short[c[0]]
 			if !ok {
 				return Request{}, errors.Errorf("unknown short command %s (0x%x)", string(c[0]), c[0])
 			}
@@ -115,7 +156,20 @@ loop:
 		if err != nil {
 			return Request{}, err
 		}
-		req.Args = []string{line}
+		req.Args = []string{strings.TrimSuffix(strings.TrimLeftFunc(line, unicode.IsSpace), "\r")}
+		return req, nil
+	}
+
+	if cmd.TerminatorArg {
+		args, err := readArgs(r, cmd.Args-1)
+		if err != nil {
+			return Request{}, err
+		}
+		terminator, err := readTerminator(r)
+		if err != nil {
+			return Request{}, err
+		}
+		req.Args = append(args, terminator)
 		return req, nil
 	}
 
@@ -128,6 +182,35 @@ loop:
 	return req, nil
 }
 
+// readTerminator reads the terminator argument of send_cmd_rx. The terminator is the character that follows the
+// separator of the previous argument, it may also be a whitespace character, e.g. a carriage return. If the
+// terminator is not a whitespace character, the rest of the word is read, since rigctld also accepts the number of
+// bytes to read instead of a terminator.
+func readTerminator(r io.Reader) (string, error) {
+	c := make([]byte, 1)
+	n, err := r.Read(c)
+	if n != 1 || err == io.EOF {
+		return "", io.EOF
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "read terminator")
+	}
+	terminator := string(c)
+	if unicode.IsSpace(rune(c[0])) {
+		return terminator, nil
+	}
+	for {
+		n, err := r.Read(c)
+		if n != 1 || err == io.EOF || unicode.IsSpace(rune(c[0])) {
+			return terminator, nil
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "read terminator")
+		}
+		terminator += string(c)
+	}
+}
+
 func skipLine(r io.Reader) error {
 	c := make([]byte, 1)
 	for {
@@ -201,12 +284,16 @@ func readLine(r io.Reader) (string, error) {
 }
 
 func readLongCommand(r io.Reader) (Command, error) {
+	return rigCommands.readLongCommand(r)
+}
+
+func (t commandTable) readLongCommand(r io.Reader) (Command, error) {
 	name, err := readWord(r)
 	if err != nil {
 		return Command{}, err
 	}
 
-	cmd, ok := LongCommands[name]
+	cmd, ok := t.long[name]
 	if !ok {
 		return Command{}, errors.Errorf("unknown long command %s", name)
 	}
@@ -243,6 +330,12 @@ func NewResponseReader(r io.Reader) ResponseReader {
 	}
 }
 
+// NewRotatorResponseReader returns a ResponseReader for responses of a rotctld server. The rotctld responses
+// use the same framing as the rigctld responses.
+func NewRotatorResponseReader(r io.Reader) ResponseReader {
+	return NewResponseReader(r)
+}
+
 type responseReader struct {
 	r       io.Reader
 	scanner *bufio.Scanner
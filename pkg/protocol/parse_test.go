@@ -41,6 +41,55 @@ fmv
 	assert.Equal(t, io.EOF, err)
 }
 
+func TestRequestReaderKeepsCarriageReturnTerminator(t *testing.T) {
+	buffer := bytes.NewBufferString("\\send_cmd_rx FA; \r\nf\r\n\\send_morse CQ\r\n")
+	expectedRequests := []Request{
+		{Command: LongCommand("send_cmd_rx"), Args: []string{"FA;", "\r"}},
+		{Command: ShortCommand("f")},
+		{Command: LongCommand("send_morse"), Args: []string{"CQ"}},
+	}
+	reader := NewRequestReader(buffer)
+
+	for i, expected := range expectedRequests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			req, err := reader.ReadRequest()
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, req)
+		})
+	}
+
+	_, err := reader.ReadRequest()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRotatorRequestReader(t *testing.T) {
+	buffer := bytes.NewBufferString(`P 180 10
+p
++\move 16 50
+S
+`)
+	expectedRequests := []Request{
+		{Command: RotatorShortCommand("P"), Args: []string{"180", "10"}},
+		{Command: RotatorShortCommand("p")},
+		{Command: RotatorLongCommand("move"), Args: []string{"16", "50"}, ExtendedSeparator: "\n"},
+		{Command: RotatorShortCommand("S")},
+	}
+	reader := NewRotatorRequestReader(buffer)
+
+	for i, expected := range expectedRequests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			req, err := reader.ReadRequest()
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected, req)
+		})
+	}
+
+	_, err := reader.ReadRequest()
+	assert.Equal(t, io.EOF, err)
+}
+
 func TestEmptyRequestReader(t *testing.T) {
 	buffer := bytes.NewBufferString("")
 	reader := NewRequestReader(buffer)
@@ -69,6 +118,11 @@ func TestNextRequest(t *testing.T) {
 		{"extended long command newline", "+\\get_mode", Request{Command: LongCommand("get_mode"), ExtendedSeparator: "\n"}, true},
 		{"line command", "\\send_morse a b c", Request{Command: LongCommand("send_morse"), Args: []string{"a b c"}}, true},
 		{"line command with newline", "\\send_morse a b c\nfmv", Request{Command: LongCommand("send_morse"), Args: []string{"a b c"}}, true},
+		{"short line command", "w FA;", Request{Command: ShortCommand("w"), Args: []string{"FA;"}}, true},
+		{"raw command with escapes", "\\send_cmd \\0xFE\\0xFE\\0x94\\0xE0\\0x03\\0xFD", Request{Command: LongCommand("send_cmd"), Args: []string{"\\0xFE\\0xFE\\0x94\\0xE0\\0x03\\0xFD"}}, true},
+		{"raw command with terminator", "\\send_cmd_rx FA; ;", Request{Command: LongCommand("send_cmd_rx"), Args: []string{"FA;", ";"}}, true},
+		{"raw command with whitespace terminator", "\\send_cmd_rx FA; \r", Request{Command: LongCommand("send_cmd_rx"), Args: []string{"FA;", "\r"}}, true},
+		{"raw command with number of bytes", "W FA; 14 f", Request{Command: ShortCommand("W"), Args: []string{"FA;", "14"}}, true},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
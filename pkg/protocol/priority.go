@@ -0,0 +1,157 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Priority defines the order in which requests are sent by a Transceiver.
+type Priority int
+
+const (
+	// PriorityAuto lets the Transceiver derive the priority from the context or the command.
+	PriorityAuto Priority = iota
+	// PriorityPoll is used for background polling.
+	PriorityPoll
+	// PriorityGet is used for interactive reading requests.
+	PriorityGet
+	// PrioritySet is used for interactive requests that change the state of the rig.
+	PrioritySet
+
+	priorityCount = int(PrioritySet) + 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityAuto:
+		return "auto"
+	case PriorityPoll:
+		return "poll"
+	case PriorityGet:
+		return "get"
+	case PrioritySet:
+		return "set"
+	default:
+		return "unknown"
+	}
+}
+
+// Validate checks if this is one of the defined priorities.
+func (p Priority) Validate() error {
+	if p < PriorityAuto || int(p) >= priorityCount {
+		return fmt.Errorf("%w: unknown priority %d", ErrInvalidParameter, int(p))
+	}
+	return nil
+}
+
+// EffectivePriority returns the priority of this request. If no explicit priority is set,
+// the priority is derived from the command.
+func (r *Request) EffectivePriority() Priority {
+	if r.Priority != PriorityAuto {
+		return r.Priority
+	}
+	if r.ReadOnly() {
+		return PriorityGet
+	}
+	return PrioritySet
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of the given context that carries the given priority. The Transceiver uses this priority
+// for all requests that do not define an explicit priority.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority carried by the given context or PriorityAuto.
+func PriorityFromContext(ctx context.Context) Priority {
+	priority, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok {
+		return PriorityAuto
+	}
+	return priority
+}
+
+// maxQueueWait is the maximum time a transmission waits in the queue before it is sent regardless of its priority.
+const maxQueueWait = 500 * time.Millisecond
+
+type sendQueue struct {
+	lock    *sync.Mutex
+	queues  [priorityCount][]transmission
+	signal  chan struct{}
+	maxWait time.Duration
+}
+
+func newSendQueue(maxWait time.Duration) *sendQueue {
+	return &sendQueue{
+		lock:    new(sync.Mutex),
+		signal:  make(chan struct{}, 1),
+		maxWait: maxWait,
+	}
+}
+
+func (q *sendQueue) push(tx transmission) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.queues[tx.priority] = append(q.queues[tx.priority], tx)
+	q.notify()
+}
+
+// pop returns the next transmission that should be sent. Transmissions with a higher priority are preferred, but
+// transmissions that waited longer than maxWait are sent first to prevent starvation.
+func (q *sendQueue) pop() (transmission, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	next := -1
+	now := time.Now()
+	for p := range q.queues {
+		if len(q.queues[p]) == 0 {
+			continue
+		}
+		head := q.queues[p][0]
+		if now.Sub(head.enqueued) <= q.maxWait {
+			continue
+		}
+		if next == -1 || head.enqueued.Before(q.queues[next][0].enqueued) {
+			next = p
+		}
+	}
+	if next == -1 {
+		for p := len(q.queues) - 1; p >= 0; p-- {
+			if len(q.queues[p]) > 0 {
+				next = p
+				break
+			}
+		}
+	}
+	if next == -1 {
+		return transmission{}, false
+	}
+
+	result := q.queues[next][0]
+	q.queues[next] = q.queues[next][1:]
+	if q.len() > 0 {
+		q.notify()
+	}
+	return result, true
+}
+
+func (q *sendQueue) len() int {
+	result := 0
+	for _, queue := range q.queues {
+		result += len(queue)
+	}
+	return result
+}
+
+func (q *sendQueue) notify() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
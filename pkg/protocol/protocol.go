@@ -19,21 +19,41 @@ type Command struct {
 	Long                 string
 	Args                 int
 	ArgsInLine           bool
+	TerminatorArg        bool
 	InvalidatesCommand   string
+	InvalidatesAll       bool
 	HasSubCommand        bool
 	SupportsExtendedMode bool
 	Cacheable            bool
 }
 
+// ReadOnly indicates if this command only reads the state of the rig.
+func (c Command) ReadOnly() bool {
+	if c.InvalidatesCommand != "" || c.InvalidatesAll {
+		return false
+	}
+	switch {
+	case c.Cacheable:
+		return true
+	case strings.HasPrefix(c.Long, "get_"), strings.HasPrefix(c.Long, "dump_"):
+		return true
+	case c.Long == "chk_vfo", c.Long == "power2mW", c.Long == "mW2power", c.Long == "password":
+		return true
+	default:
+		return false
+	}
+}
+
 type Request struct {
 	Command
 	ExtendedSeparator string
 	Args              []string
+	Priority          Priority
 }
 
 func (r *Request) Key() CommandKey {
 	if r.HasSubCommand && len(r.Args) > 0 {
-		return subCommandKey(r.Long, r.Args[0])
+		return subCommandKey(r.Long, r.subCommand())
 	}
 	return CommandKey(r.Long)
 }
@@ -41,13 +61,34 @@ func (r *Request) Key() CommandKey {
 func (r *Request) InvalidatedKey() CommandKey {
 	if r.InvalidatesCommand != "" {
 		if r.HasSubCommand && len(r.Args) > 0 {
-			return subCommandKey(r.InvalidatesCommand, r.Args[0])
+			return subCommandKey(r.InvalidatesCommand, r.subCommand())
 		}
 		return CommandKey(r.InvalidatesCommand)
 	}
 	return NoCommand
 }
 
+// InvalidatedKeys returns the keys of all responses that are invalidated by this request, including the side effects
+// of the command.
+func (r *Request) InvalidatedKeys() []CommandKey {
+	var result []CommandKey
+	if key := r.InvalidatedKey(); key != NoCommand {
+		result = append(result, key)
+	}
+	return append(result, SideEffects[r.Long]...)
+}
+
+func (r *Request) subCommand() string {
+	if !r.ArgsInLine {
+		return r.Args[0]
+	}
+	fields := strings.Fields(r.Args[0])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
 func (r *Request) LongFormat() string {
 	return strings.Join(append([]string{"\\" + r.Long}, r.Args...), " ")
 }
@@ -63,6 +104,19 @@ type Response struct {
 	Result  string
 }
 
+// Lines returns the data lines of this response as sent by rigctld. Keyed lines are joined with their key.
+func (r *Response) Lines() []string {
+	result := make([]string, len(r.Data))
+	for i, value := range r.Data {
+		if i < len(r.Keys) && r.Keys[i] != "" {
+			result[i] = r.Keys[i] + ": " + value
+		} else {
+			result[i] = value
+		}
+	}
+	return result
+}
+
 func (r *Response) Format() string {
 	if len(r.Data) == 0 || r.Result != "0" {
 		return fmt.Sprintf("RPRT %s", r.Result)
@@ -85,66 +139,3 @@ func (r *Response) ExtendedFormat(separator string) string {
 
 	return buffer.String()
 }
-
-type Error struct {
-	code    string
-	message string
-}
-
-func (e Error) Error() string {
-	return fmt.Sprintf("hamlib error %s: %s", e.code, e.message)
-}
-
-func newError(code string) Error {
-	message, ok := errorMessagesByCode[code]
-	if !ok {
-		message = "Unknown error"
-	}
-	return Error{code: code, message: message}
-}
-
-var errorMessagesByCode = map[string]string{
-	"-1":  "Invalid parameter",
-	"-2":  "Invalid configuration",
-	"-3":  "Memory shortage",
-	"-4":  "Feature not implemented",
-	"-5":  "Communication timed out",
-	"-6":  "IO error",
-	"-7":  "Internal Hamlib error",
-	"-8":  "Protocol error",
-	"-9":  "Command rejected by the rig",
-	"-10": "Command performed, but arg truncated, result not guaranteed",
-	"-11": "Feature not available",
-	"-12": "Target VFO unaccessible",
-	"-13": "Communication bus error",
-	"-14": "Communication bus collision",
-	"-15": "NULL RIG handle or invalid pointer parameter",
-	"-16": "Invalid VFO",
-	"-17": "Argument out of domain of func",
-	"-18": "Function deprecated",
-	"-19": "Security error password not provided or crypto failure",
-	"-20": "Rig is not powered on",
-}
-
-var (
-	ErrInvalidParameter          = newError("-1")
-	ErrInvalidConfiguration      = newError("-2")
-	ErrMemoryShortage            = newError("-3")
-	ErrFeatureNotImplemented     = newError("-4")
-	ErrCommunicationTimedOut     = newError("-5")
-	ErrIOError                   = newError("-6")
-	ErrInternalHamlibError       = newError("-7")
-	ErrProtocolError             = newError("-8")
-	ErrCommandRejectedByRig      = newError("-9")
-	ErrArgTruncated              = newError("-10")
-	ErrFeatureNotAvailable       = newError("-11")
-	ErrTargetVFOUnaccessible     = newError("-12")
-	ErrCommunicationBusError     = newError("-13")
-	ErrCommunicationBusCollision = newError("-14")
-	ErrNullRigHandle             = newError("-15")
-	ErrInvalidVFO                = newError("-16")
-	ErrArgumentOutOfDomain       = newError("-17")
-	ErrFunctionDeprecated        = newError("-18")
-	ErrSecurityError             = newError("-19")
-	ErrRigNotPoweredOn           = newError("-20")
-)
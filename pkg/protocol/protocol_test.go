@@ -1,10 +1,15 @@
 package protocol
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ftl/rigproxy/pkg/test"
 )
@@ -33,6 +38,22 @@ func TestInvalidatingCommandKeyWithSubCommand(t *testing.T) {
 	assert.Equal(t, CommandKey("get_d_first"), req.InvalidatedKey())
 }
 
+func TestInvalidatingCommandKeyWithSubCommandInLine(t *testing.T) {
+	req := Request{Command: LongCommand("set_channel"), Args: []string{"5 145500000 FM 15000"}}
+	assert.Equal(t, CommandKey("get_channel_5"), req.InvalidatedKey())
+}
+
+func TestInvalidatedKeysContainSideEffects(t *testing.T) {
+	req := Request{Command: LongCommand("set_freq"), Args: []string{"145500000"}}
+	assert.Equal(t, []CommandKey{"get_freq", "get_dcd", "get_level_STRENGTH", "get_level_SWR", "get_rig_info"}, req.InvalidatedKeys())
+
+	req = Request{Command: LongCommand("set_ptt"), Args: []string{"1"}}
+	assert.Equal(t, []CommandKey{"get_ptt", "get_rig_info"}, req.InvalidatedKeys())
+
+	req = Request{Command: LongCommand("vfo_op"), Args: []string{"XCHG"}}
+	assert.Equal(t, []CommandKey{"get_freq", "get_mode", "get_vfo", "get_split_vfo", "get_rig_info"}, req.InvalidatedKeys())
+}
+
 func TestTransceiverSendReceiveRoundtrip(t *testing.T) {
 	buffer := test.NewBuffer("get_freq:\nFrequency: 3720000\nRPRT 0\nRPRT 11\n")
 
@@ -56,3 +77,166 @@ func TestTransceiverSendReceiveRoundtrip(t *testing.T) {
 
 	buffer.AssertWritten(t, "+\\get_freq\n+\\get_freq\n")
 }
+
+func TestEffectivePriority(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		request  Request
+		expected Priority
+	}{
+		{"set", Request{Command: LongCommand("set_freq")}, PrioritySet},
+		{"get", Request{Command: LongCommand("get_freq")}, PriorityGet},
+		{"action", Request{Command: LongCommand("send_morse")}, PrioritySet},
+		{"dump", Request{Command: LongCommand("dump_state")}, PriorityGet},
+		{"raw", Request{Command: LongCommand("send_cmd")}, PrioritySet},
+		{"explicit", Request{Command: LongCommand("get_freq"), Priority: PriorityPoll}, PriorityPoll},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.expected, tC.request.EffectivePriority())
+		})
+	}
+}
+
+func TestSendQueuePrefersHigherPriority(t *testing.T) {
+	queue := newSendQueue(time.Minute)
+	now := time.Now()
+	queue.push(transmission{request: Request{Command: LongCommand("get_level")}, priority: PriorityPoll, enqueued: now})
+	queue.push(transmission{request: Request{Command: LongCommand("get_freq")}, priority: PriorityGet, enqueued: now})
+	queue.push(transmission{request: Request{Command: LongCommand("set_freq")}, priority: PrioritySet, enqueued: now})
+	queue.push(transmission{request: Request{Command: LongCommand("get_mode")}, priority: PriorityPoll, enqueued: now})
+
+	expected := []string{"set_freq", "get_freq", "get_level", "get_mode"}
+	for _, command := range expected {
+		tx, ok := queue.pop()
+		assert.True(t, ok)
+		assert.Equal(t, command, tx.request.Long)
+	}
+	_, ok := queue.pop()
+	assert.False(t, ok)
+}
+
+func TestSendQueuePreventsStarvation(t *testing.T) {
+	queue := newSendQueue(100 * time.Millisecond)
+	now := time.Now()
+	queue.push(transmission{request: Request{Command: LongCommand("get_level")}, priority: PriorityPoll, enqueued: now.Add(-time.Second)})
+	queue.push(transmission{request: Request{Command: LongCommand("set_freq")}, priority: PrioritySet, enqueued: now})
+
+	tx, ok := queue.pop()
+	assert.True(t, ok)
+	assert.Equal(t, "get_level", tx.request.Long)
+}
+
+func TestTransceiverDropsCanceledRequest(t *testing.T) {
+	buffer := test.NewBuffer("")
+
+	trx := NewTransceiver(buffer)
+	defer trx.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := trx.Send(ctx, Request{Command: ShortCommand("f")})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	buffer.AssertWritten(t, "")
+}
+
+func TestTransceiverRejectsUnknownPriority(t *testing.T) {
+	buffer := test.NewBuffer("")
+
+	trx := NewTransceiver(buffer)
+	defer trx.Close()
+
+	_, err := trx.Send(context.Background(), Request{Command: ShortCommand("f"), Priority: Priority(4)})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, err = trx.Send(WithPriority(context.Background(), Priority(-1)), Request{Command: ShortCommand("f")})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	buffer.AssertWritten(t, "")
+}
+
+func TestSendOnClosedTransceiver(t *testing.T) {
+	trx := NewPollingTransceiver(test.NewBuffer(""), time.Hour, time.Second)
+	trx.Close()
+
+	_, err := trx.Send(context.Background(), Request{Command: ShortCommand("f")})
+
+	assert.ErrorIs(t, err, ErrTransceiverClosed)
+}
+
+func TestTransceiverKeepsResponsesInSyncWhenCallerGivesUp(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+
+	trx := NewTransceiver(local)
+	defer trx.Close()
+
+	firstRequestRead := make(chan struct{})
+	go func() {
+		r := bufio.NewReader(remote)
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "+\\get_freq\n", line)
+		close(firstRequestRead)
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(remote, "get_freq:\nFrequency: 3720000\nRPRT 0\n")
+
+		line, err = r.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "+\\get_mode\n", line)
+		fmt.Fprint(remote, "get_mode:\nMode: USB\nPassband: 2400\nRPRT 0\n")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := trx.Send(ctx, Request{Command: ShortCommand("f")})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	<-firstRequestRead
+
+	resp, err := trx.Send(context.Background(), Request{Command: ShortCommand("m")})
+	require.NoError(t, err)
+	assert.Equal(t, CommandKey("get_mode"), resp.Command)
+	assert.Equal(t, []string{"USB", "2400"}, resp.Data)
+}
+
+func TestTransceiverRequestTimeout(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	defer remote.Close()
+	go bufio.NewReader(remote).ReadString('\n')
+
+	trx := NewTransceiver(local, WithRequestTimeout(10*time.Millisecond))
+	defer trx.Close()
+
+	_, err := trx.Send(context.Background(), Request{Command: ShortCommand("f")})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type recordedTransmission struct {
+	command CommandKey
+	err     error
+}
+
+type recordingMetrics []recordedTransmission
+
+func (m *recordingMetrics) ObserveTransmission(command CommandKey, _ time.Duration, err error) {
+	*m = append(*m, recordedTransmission{command, err})
+}
+
+func TestTransceiverReportsMetrics(t *testing.T) {
+	metrics := new(recordingMetrics)
+	trx := NewTransceiver(test.NewBuffer("get_freq:\nFrequency: 3720000\nRPRT 0\n"), WithMetrics(metrics))
+	defer trx.Close()
+
+	_, err := trx.Send(context.Background(), Request{Command: ShortCommand("f")})
+	require.NoError(t, err)
+	trx.Close()
+	_, err = trx.Send(context.Background(), Request{Command: ShortCommand("m")})
+	require.Error(t, err)
+
+	assert.Equal(t, recordingMetrics{
+		{CommandKey("get_freq"), nil},
+		{CommandKey("get_mode"), ErrTransceiverClosed},
+	}, *metrics)
+}
@@ -4,69 +4,13 @@ import (
 	"strconv"
 )
 
-type HamlibError string
-
-func (e HamlibError) Error() string {
-	message, ok := HamlibErrorMessages[string(e)]
-	if ok {
-		return message
-	}
-	return "unknown hamlib error: " + string(e)
-}
-
-const (
-	InvalidParameter             HamlibError = "-1"
-	InvalidConfiguration         HamlibError = "-2"
-	MemoryShortage               HamlibError = "-3"
-	FeatureNotImplemented        HamlibError = "-4"
-	CommunicationTimedOut        HamlibError = "-5"
-	IOError                      HamlibError = "-6"
-	InternalHamlibError          HamlibError = "-7"
-	ProtocolError                HamlibError = "-8"
-	CommandRejectedByTheRig      HamlibError = "-9"
-	CommandPerformedButTruncated HamlibError = "-10"
-	FeatureNotAvailable          HamlibError = "-11"
-	TargetVFOUnaccessible        HamlibError = "-12"
-	CommunicationBusError        HamlibError = "-13"
-	CommunicationBusCollision    HamlibError = "-14"
-	NullRigHandle                HamlibError = "-15"
-	InvalidVFO                   HamlibError = "-16"
-	ArgumentOutOfDomain          HamlibError = "-17"
-	FunctionDeprecated           HamlibError = "-18"
-	SecurityError                HamlibError = "-19"
-	RigNotPoweredOn              HamlibError = "-20"
-)
-
-var HamlibErrorMessages = map[string]string{
-	"0":   "Command completed successfully",
-	"-1":  "Invalid parameter",
-	"-2":  "Invalid configuration",
-	"-3":  "Memory shortage",
-	"-4":  "Feature not implemented",
-	"-5":  "Communication timed out",
-	"-6":  "IO error",
-	"-7":  "Internal Hamlib error",
-	"-8":  "Protocol error",
-	"-9":  "Command rejected by the rig",
-	"-10": "Command performed, but arg truncated, result not guaranteed",
-	"-11": "Feature not available",
-	"-12": "Target VFO unaccessible",
-	"-13": "Communication bus error",
-	"-14": "Communication bus collision",
-	"-15": "NULL RIG handle or invalid pointer parameter",
-	"-16": "Invalid VFO",
-	"-17": "Argument out of domain of func",
-	"-18": "Function deprecated",
-	"-19": "Security error password not provided or crypto failure",
-	"-20": "Rig is not powered on",
-}
-
 func OKResponse(cmd CommandKey) Response {
 	return Response{Command: cmd, Result: "0"}
 }
 
-func ErrorResponse(cmd CommandKey, err HamlibError) Response {
-	return Response{Command: cmd, Result: string(err)}
+// ErrorResponse returns a response for the given command that reports the Hamlib error code of the given error.
+func ErrorResponse(cmd CommandKey, err error) Response {
+	return Response{Command: cmd, Result: strconv.Itoa(ErrorCode(err))}
 }
 
 func GetFreqResponse(frequency int) Response {
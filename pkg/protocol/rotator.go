@@ -0,0 +1,219 @@
+package protocol
+
+// The rotctl commands, see https://github.com/Hamlib/Hamlib/blob/master/tests/rotctl_parse.c static struct test_table
+// for the official list.
+
+var (
+	RotatorShortCommands = make(map[byte]Command)
+	RotatorLongCommands  = make(map[string]Command)
+	RotatorCommands      = []Command{
+		{
+			Short:                'P',
+			Long:                 "set_pos",
+			Args:                 2,
+			InvalidatesCommand:   "get_pos",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'p',
+			Long:                 "get_pos",
+			Cacheable:            true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'M',
+			Long:                 "move",
+			Args:                 2,
+			InvalidatesCommand:   "get_pos",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'S',
+			Long:                 "stop",
+			InvalidatesCommand:   "get_pos",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'K',
+			Long:                 "park",
+			InvalidatesCommand:   "get_pos",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'R',
+			Long:                 "reset",
+			Args:                 1,
+			InvalidatesCommand:   "get_pos",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                's',
+			Long:                 "get_status",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short: 'C',
+			Long:  "set_conf",
+			Args:  2,
+		},
+		{
+			Short:                'V',
+			Long:                 "set_level",
+			Args:                 2,
+			InvalidatesCommand:   "get_level",
+			HasSubCommand:        true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'v',
+			Long:                 "get_level",
+			Args:                 1,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'U',
+			Long:                 "set_func",
+			Args:                 2,
+			InvalidatesCommand:   "get_func",
+			HasSubCommand:        true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'u',
+			Long:                 "get_func",
+			Args:                 1,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'X',
+			Long:                 "set_parm",
+			Args:                 2,
+			InvalidatesCommand:   "get_parm",
+			HasSubCommand:        true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'x',
+			Long:                 "get_parm",
+			Args:                 1,
+			HasSubCommand:        true,
+			Cacheable:            true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:     '_',
+			Long:      "get_info",
+			Cacheable: true,
+		},
+		{
+			Short:                '1',
+			Long:                 "dump_caps",
+			Cacheable:            true,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                0x8f,
+			Long:                 "dump_state",
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:          'w',
+			Long:           "send_cmd",
+			Args:           1,
+			ArgsInLine:     true,
+			InvalidatesAll: true,
+		},
+		{
+			Short:                'L',
+			Long:                 "lonlat2loc",
+			Args:                 3,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'l',
+			Long:                 "loc2lonlat",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'D',
+			Long:                 "dms2dec",
+			Args:                 4,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'd',
+			Long:                 "dec2dms",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'E',
+			Long:                 "dmmm2dec",
+			Args:                 4,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'e',
+			Long:                 "dec2dmmm",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'B',
+			Long:                 "qrb",
+			Args:                 4,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'A',
+			Long:                 "a_sp2a_lp",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                'a',
+			Long:                 "d_sp2d_lp",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short:                0x8c,
+			Long:                 "pause",
+			Args:                 1,
+			SupportsExtendedMode: true,
+		},
+		{
+			Short: 0x98,
+			Long:  "password",
+			Args:  1,
+		},
+	}
+)
+
+func init() {
+	for _, cmd := range RotatorCommands {
+		RotatorShortCommands[cmd.Short] = cmd
+		RotatorLongCommands[cmd.Long] = cmd
+	}
+}
+
+func RotatorShortCommand(s string) Command {
+	cmd, ok := RotatorShortCommands[byte(s[0])]
+	if !ok {
+		panic("unknown rotator command " + s)
+	}
+	return cmd
+}
+
+func RotatorLongCommand(s string) Command {
+	cmd, ok := RotatorLongCommands[s]
+	if !ok {
+		panic("unknown rotator command " + s)
+	}
+	return cmd
+}
@@ -5,33 +5,78 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 )
 
 type Transceiver struct {
-	rw       io.ReadWriter
-	outgoing chan transmission
-	polling  polling
-	closed   chan struct{}
+	rw        io.ReadWriter
+	outgoing  *sendQueue
+	polling   polling
+	closed    chan struct{}
+	closeOnce *sync.Once
+	logger    *slog.Logger
+	timeout   time.Duration
+	metrics   Metrics
 }
 
+// TransceiverOption configures optional features of a Transceiver.
+type TransceiverOption func(*Transceiver)
+
+// WithLogger lets the Transceiver use the given logger. Without logger, slog.Default() is used.
+func WithLogger(logger *slog.Logger) TransceiverOption {
+	return func(t *Transceiver) {
+		t.logger = logger
+	}
+}
+
+// WithRequestTimeout limits the time to wait for the response to each request, including the time the request waits in
+// the send queue. The timeout applies in addition to the deadline of the context that is passed to Send.
+func WithRequestTimeout(timeout time.Duration) TransceiverOption {
+	return func(t *Transceiver) {
+		t.timeout = timeout
+	}
+}
+
+// Metrics receives the outcome of every request that is sent through a Transceiver.
+type Metrics interface {
+	ObserveTransmission(command CommandKey, latency time.Duration, err error)
+}
+
+// WithMetrics reports the latency and the error of every request to the given Metrics.
+func WithMetrics(metrics Metrics) TransceiverOption {
+	return func(t *Transceiver) {
+		t.metrics = metrics
+	}
+}
+
+// ErrTransceiverClosed is returned when a request is sent through a closed Transceiver.
+var ErrTransceiverClosed = errors.New("transceiver already closed")
+
 type transmission struct {
+	ctx      context.Context
 	request  Request
+	priority Priority
+	enqueued time.Time
 	response chan Response
 	err      chan error
 }
 
-func NewTransceiver(rw io.ReadWriter) *Transceiver {
+func NewTransceiver(rw io.ReadWriter, opts ...TransceiverOption) *Transceiver {
 	result := Transceiver{
 		rw:       rw,
-		outgoing: make(chan transmission, 1),
+		outgoing: newSendQueue(maxQueueWait),
 		polling: polling{
 			tick: time.NewTicker(1 * time.Second),
 		},
-		closed: make(chan struct{}),
+		closed:    make(chan struct{}),
+		closeOnce: new(sync.Once),
 	}
 	result.polling.tick.Stop()
+	for _, opt := range opts {
+		opt(&result)
+	}
 
 	go result.start()
 
@@ -39,20 +84,19 @@ func NewTransceiver(rw io.ReadWriter) *Transceiver {
 }
 
 func NewPollingTransceiver(rw io.ReadWriter, interval time.Duration, timeout time.Duration, requests ...PollRequest) *Transceiver {
-	result := Transceiver{
-		rw:       rw,
-		outgoing: make(chan transmission),
-		polling: polling{
+	return NewTransceiver(rw, WithPolling(interval, timeout, requests...))
+}
+
+// WithPolling sends the given poll requests periodically in the given interval. Each poll request must be answered
+// within the given timeout.
+func WithPolling(interval time.Duration, timeout time.Duration, requests ...PollRequest) TransceiverOption {
+	return func(t *Transceiver) {
+		t.polling = polling{
 			tick:     time.NewTicker(interval),
 			timeout:  timeout,
 			requests: requests,
-		},
-		closed: make(chan struct{}),
+		}
 	}
-
-	go result.start()
-
-	return &result
 }
 
 func (t *Transceiver) start() {
@@ -61,31 +105,14 @@ func (t *Transceiver) start() {
 		select {
 		case <-t.closed:
 			return
-		case tx := <-t.outgoing:
-			_, err := fmt.Fprintln(t.rw, tx.request.ExtendedFormat())
-			if err != nil {
-				log.Println("transmit:", err)
-				tx.err <- fmt.Errorf("transmission of request failed: %w", err)
+		case <-t.outgoing.signal:
+			tx, ok := t.outgoing.pop()
+			if !ok {
+				continue
 			}
-			resp, err := r.ReadResponse(tx.request.SupportsExtendedMode)
-			if err == io.EOF {
-				log.Println("receive: connection closed")
-				tx.err <- fmt.Errorf("connection closed while waiting for response: %w", err)
-				close(t.closed)
+			if !t.transmit(r, tx) {
+				t.Close()
 				return
-			} else if err != nil {
-				log.Println("receive:", err)
-				tx.err <- fmt.Errorf("receiving of response failed: %w", err)
-			} else if resp.Result != "0" {
-				err := newError(resp.Result)
-				log.Printf("%v", err)
-				tx.err <- fmt.Errorf("request failed: %w", err)
-			} else {
-				select {
-				case tx.response <- resp:
-				default:
-					log.Printf("could not queue response to transmission, nobody is listening: %+v", resp)
-				}
 			}
 		case <-t.polling.tick.C:
 			go t.poll()
@@ -93,18 +120,94 @@ func (t *Transceiver) start() {
 	}
 }
 
+// transmit sends the request of the given transmission and waits for the response. The response is always read
+// completely, even if the sender already gave up, to keep the response stream in sync with the requests.
+// transmit returns false if the connection cannot be used anymore.
+func (t *Transceiver) transmit(r ResponseReader, tx transmission) bool {
+	if err := tx.ctx.Err(); err != nil {
+		t.log().Warn("dropping expired request", "command", tx.request.Key(), "error", err)
+		tx.err <- err
+		return true
+	}
+
+	_, err := fmt.Fprintln(t.rw, tx.request.ExtendedFormat())
+	if err != nil {
+		t.log().Error("cannot transmit request", "command", tx.request.Key(), "error", err)
+		tx.err <- fmt.Errorf("transmission of request failed: %w", err)
+		return false
+	}
+
+	resp, err := r.ReadResponse(tx.request.SupportsExtendedMode)
+	if err == io.EOF {
+		t.log().Info("connection closed while waiting for response", "command", tx.request.Key())
+		tx.err <- fmt.Errorf("connection closed while waiting for response: %w", err)
+		return false
+	} else if err != nil {
+		t.log().Warn("cannot receive response", "command", tx.request.Key(), "error", err)
+		tx.err <- fmt.Errorf("receiving of response failed: %w", err)
+	} else {
+		tx.response <- resp
+	}
+
+	if tx.ctx.Err() != nil {
+		t.log().Warn("response arrived after the request was canceled", "command", tx.request.Key(), "response", resp.Format())
+	}
+	return true
+}
+
 func (t *Transceiver) Send(ctx context.Context, req Request) (Response, error) {
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	if t.metrics == nil {
+		return t.send(ctx, req)
+	}
+
+	startTime := time.Now()
+	resp, err := t.send(ctx, req)
+	t.metrics.ObserveTransmission(req.Key(), time.Since(startTime), err)
+	return resp, err
+}
+
+func (t *Transceiver) send(ctx context.Context, req Request) (Response, error) {
 	select {
 	case <-t.closed:
-		return Response{}, errors.New("transceiver already closed")
+		return Response{}, ErrTransceiverClosed
 	default:
 	}
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
 
-	tx := transmission{request: req, response: make(chan Response), err: make(chan error)}
-	t.outgoing <- tx
+	if req.Priority == PriorityAuto {
+		req.Priority = PriorityFromContext(ctx)
+	}
+	if err := req.Priority.Validate(); err != nil {
+		return Response{}, err
+	}
+	tx := transmission{
+		ctx:      ctx,
+		request:  req,
+		priority: req.EffectivePriority(),
+		enqueued: time.Now(),
+		response: make(chan Response, 1),
+		err:      make(chan error, 1),
+	}
+	t.outgoing.push(tx)
 	select {
 	case <-ctx.Done():
 		return Response{}, ctx.Err()
+	case <-t.closed:
+		select {
+		case err := <-tx.err:
+			return Response{}, err
+		case resp := <-tx.response:
+			return resp, nil
+		default:
+			return Response{}, ErrTransceiverClosed
+		}
 	case err := <-tx.err:
 		return Response{}, err
 	case resp := <-tx.response:
@@ -112,14 +215,18 @@ func (t *Transceiver) Send(ctx context.Context, req Request) (Response, error) {
 	}
 }
 
+func (t *Transceiver) log() *slog.Logger {
+	if t.logger == nil {
+		return slog.Default()
+	}
+	return t.logger
+}
+
 func (t *Transceiver) Close() {
-	select {
-	case <-t.closed:
-		return
-	default:
+	t.closeOnce.Do(func() {
 		t.polling.tick.Stop()
 		close(t.closed)
-	}
+	})
 }
 
 func (t *Transceiver) WhenDone(f func()) {
@@ -171,17 +278,21 @@ func PollCommand(handler ResponseHandler, command string, args ...string) PollRe
 
 func (t Transceiver) poll() {
 	for _, r := range t.polling.requests {
-		ctx, _ := context.WithTimeout(context.Background(), t.polling.timeout)
-		request := Request{Command: r.Command, Args: r.Args}
+		ctx, cancel := context.WithTimeout(context.Background(), t.polling.timeout)
+		request := Request{Command: r.Command, Args: r.Args, Priority: PriorityPoll}
 		response, err := t.Send(ctx, request)
+		cancel()
+		if err == nil {
+			err = ResultError(request.Key(), response.Result)
+		}
 		if err != nil {
-			log.Printf("sending poll request %s failed: %v", r.Command.Long, err)
+			t.log().Warn("sending poll request failed", "command", request.Key(), "error", err)
 			continue
 		}
 
 		err = r.Handler.Handle(request, response)
 		if err != nil {
-			log.Printf("receiving poll response %s failed: %v", r.Command.Long, err)
+			t.log().Warn("receiving poll response failed", "command", request.Key(), "error", err)
 		}
 	}
 }
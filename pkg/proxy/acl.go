@@ -0,0 +1,126 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// ACLRule grants or denies access for clients from the given network.
+type ACLRule struct {
+	Network  *net.IPNet
+	ReadOnly bool
+	Deny     bool
+}
+
+// ParseACLRule parses the given network in CIDR notation or as single IP address into an ACLRule.
+func ParseACLRule(network string, readOnly bool, deny bool) (ACLRule, error) {
+	if !strings.Contains(network, "/") {
+		ip := net.ParseIP(network)
+		if ip == nil {
+			return ACLRule{}, fmt.Errorf("invalid network %q", network)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+		return ACLRule{Network: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, ReadOnly: readOnly, Deny: deny}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		return ACLRule{}, fmt.Errorf("invalid network %q: %w", network, err)
+	}
+	return ACLRule{Network: ipNet, ReadOnly: readOnly, Deny: deny}, nil
+}
+
+// ACL controls which clients may access the rig. The first rule that matches the client's address is applied. If
+// no rule matches, the access is denied. Clients that are not connected through IP, e.g. through a Unix domain
+// socket, are not restricted by the ACL.
+type ACL struct {
+	rules []ACLRule
+}
+
+// NewACL creates a new ACL with the given rules. An ACL without rules allows all clients.
+func NewACL(rules ...ACLRule) *ACL {
+	return &ACL{rules: rules}
+}
+
+// Check if the client with the given address may execute the given request.
+func (a *ACL) Check(addr net.Addr, req protocol.Request) error {
+	if a == nil || len(a.rules) == 0 {
+		return nil
+	}
+	ip := addrIP(addr)
+	if ip == nil {
+		return nil
+	}
+	for _, rule := range a.rules {
+		if !rule.Network.Contains(ip) {
+			continue
+		}
+		if rule.Deny {
+			return fmt.Errorf("%w: access denied for %s", protocol.ErrSecurityError, ip)
+		}
+		if rule.ReadOnly && !req.ReadOnly() {
+			return fmt.Errorf("%w: read-only access for %s", protocol.ErrSecurityError, ip)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: access denied for %s", protocol.ErrSecurityError, ip)
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	default:
+		return nil
+	}
+}
+
+// SharedACL holds the ACL that is shared by all sessions. It can be replaced while the sessions are running.
+type SharedACL struct {
+	acl atomic.Pointer[ACL]
+}
+
+// NewSharedACL creates a new SharedACL that holds the given ACL.
+func NewSharedACL(acl *ACL) *SharedACL {
+	result := new(SharedACL)
+	result.Store(acl)
+	return result
+}
+
+// Load returns the current ACL.
+func (s *SharedACL) Load() *ACL {
+	if s == nil {
+		return nil
+	}
+	return s.acl.Load()
+}
+
+// Store replaces the current ACL.
+func (s *SharedACL) Store(acl *ACL) {
+	s.acl.Store(acl)
+}
+
+// CheckACL rejects the requests that the client is not allowed to send according to the ACL that is currently held
+// by the given SharedACL.
+func CheckACL(acl *SharedACL) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			if err := acl.Load().Check(remoteAddrFromContext(ctx), req); err != nil {
+				return protocol.Response{}, err
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestACLCheck(t *testing.T) {
+	readOnlyLAN, err := ParseACLRule("192.168.1.0/24", true, false)
+	require.NoError(t, err)
+	denyHost, err := ParseACLRule("192.168.1.13", false, true)
+	require.NoError(t, err)
+	localhost, err := ParseACLRule("127.0.0.1", false, false)
+	require.NoError(t, err)
+	acl := NewACL(denyHost, readOnlyLAN, localhost)
+
+	getFreq := protocol.Request{Command: protocol.LongCommand("get_freq")}
+	setFreq := protocol.Request{Command: protocol.LongCommand("set_freq")}
+
+	testCases := []struct {
+		desc    string
+		addr    net.Addr
+		req     protocol.Request
+		allowed bool
+	}{
+		{"local get", &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, getFreq, true},
+		{"local set", &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, setFreq, true},
+		{"lan get", &net.TCPAddr{IP: net.ParseIP("192.168.1.10")}, getFreq, true},
+		{"lan set", &net.TCPAddr{IP: net.ParseIP("192.168.1.10")}, setFreq, false},
+		{"denied host", &net.TCPAddr{IP: net.ParseIP("192.168.1.13")}, getFreq, false},
+		{"no match", &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}, getFreq, false},
+		{"unix socket", &net.UnixAddr{Name: "/run/rigproxy.sock", Net: "unix"}, setFreq, true},
+		{"no address", nil, setFreq, true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := acl.Check(tC.addr, tC.req)
+			if tC.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, protocol.ErrSecurityError)
+			}
+		})
+	}
+}
+
+func TestEmptyACLAllowsAll(t *testing.T) {
+	var nilACL *ACL
+	setFreq := protocol.Request{Command: protocol.LongCommand("set_freq")}
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}
+
+	assert.NoError(t, nilACL.Check(addr, setFreq))
+	assert.NoError(t, NewACL().Check(addr, setFreq))
+}
+
+func TestParseACLRuleInvalid(t *testing.T) {
+	_, err := ParseACLRule("192.168.1", false, false)
+	assert.Error(t, err)
+	_, err = ParseACLRule("192.168.1.0/33", false, false)
+	assert.Error(t, err)
+}
+
+type remoteBuffer struct {
+	*channelReader
+	addr net.Addr
+}
+
+func (b remoteBuffer) RemoteAddr() net.Addr {
+	return b.addr
+}
+
+func TestProxyRejectsRequestsDeniedByACL(t *testing.T) {
+	trx := new(mockTransceiver)
+	lan, err := ParseACLRule("192.168.1.0/24", true, false)
+	require.NoError(t, err)
+	proxy := Proxy{
+		rwc:   remoteBuffer{addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.10")}},
+		trx:   trx,
+		cache: new(nopCache),
+		acl:   NewSharedACL(NewACL(lan)),
+	}
+
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_freq"), Args: []string{"14074000"}})
+
+	assert.ErrorIs(t, err, protocol.ErrSecurityError)
+	trx.AssertNotCalled(t, "Send")
+}
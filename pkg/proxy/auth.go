@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// Authenticator checks the tokens that clients send with the password command. The tokens can be replaced while the
+// sessions are running, already authenticated sessions stay authenticated.
+type Authenticator struct {
+	hashes atomic.Pointer[[][sha256.Size]byte]
+}
+
+// NewAuthenticator creates a new Authenticator that accepts the given tokens. Without tokens, no authentication is
+// required.
+func NewAuthenticator(tokens ...string) *Authenticator {
+	result := new(Authenticator)
+	result.SetTokens(tokens...)
+	return result
+}
+
+// SetTokens replaces the accepted tokens.
+func (a *Authenticator) SetTokens(tokens ...string) {
+	hashes := make([][sha256.Size]byte, len(tokens))
+	for i, token := range tokens {
+		hashes[i] = sha256.Sum256([]byte(token))
+	}
+	a.hashes.Store(&hashes)
+}
+
+// Required indicates if clients need to authenticate.
+func (a *Authenticator) Required() bool {
+	if a == nil {
+		return false
+	}
+	return len(*a.hashes.Load()) > 0
+}
+
+// Authenticate indicates if the given token is accepted.
+func (a *Authenticator) Authenticate(token string) bool {
+	if !a.Required() {
+		return true
+	}
+	hash := sha256.Sum256([]byte(token))
+	result := 0
+	for _, accepted := range *a.hashes.Load() {
+		result |= subtle.ConstantTimeCompare(hash[:], accepted[:])
+	}
+	return result == 1
+}
+
+// RequireAuthentication rejects the requests of clients that did not authenticate with one of the tokens accepted by
+// the given Authenticator. The password command is handled locally and never forwarded to the upstream server.
+// Clients that are connected through a Unix domain socket do not need to authenticate.
+func RequireAuthentication(auth *Authenticator) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			session := sessionFromContext(ctx)
+			if req.Key() == protocol.CommandKey("password") {
+				if len(req.Args) == 0 || !auth.Authenticate(req.Args[0]) {
+					return protocol.Response{}, fmt.Errorf("%w: authentication of %v failed", protocol.ErrSecurityError, remoteAddrFromContext(ctx))
+				}
+				if session != nil {
+					session.authenticated = true
+				}
+				return protocol.Response{Command: req.Key(), Result: "0"}, nil
+			}
+
+			authenticated := session != nil && session.authenticated
+			if auth.Required() && !authenticated && !isUnixAddr(remoteAddrFromContext(ctx)) {
+				return protocol.Response{}, fmt.Errorf("%w: not authenticated", protocol.ErrSecurityError)
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestAuthenticator(t *testing.T) {
+	auth := NewAuthenticator("secret", "other")
+
+	assert.True(t, auth.Required())
+	assert.True(t, auth.Authenticate("secret"))
+	assert.True(t, auth.Authenticate("other"))
+	assert.False(t, auth.Authenticate("wrong"))
+	assert.False(t, auth.Authenticate(""))
+
+	auth.SetTokens()
+	assert.False(t, auth.Required())
+	assert.True(t, auth.Authenticate("wrong"))
+
+	var nilAuth *Authenticator
+	assert.False(t, nilAuth.Required())
+}
+
+func TestProxyRequiresAuthentication(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:   trx,
+		cache: new(nopCache),
+		auth:  NewAuthenticator("secret"),
+	}
+	freq := protocol.Response{Data: []string{"14074000"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Once().Return(freq, nil)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	assert.ErrorIs(t, err, protocol.ErrSecurityError)
+
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("password"), Args: []string{"wrong"}})
+	assert.ErrorIs(t, err, protocol.ErrSecurityError)
+
+	resp, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("password"), Args: []string{"secret"}})
+	require.NoError(t, err)
+	assert.Equal(t, "RPRT 0", resp.Format())
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	assert.Equal(t, freq, actual)
+	trx.AssertExpectations(t)
+}
+
+func TestProxyAcceptsPasswordWithoutAuthenticator(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:   trx,
+		cache: new(nopCache),
+	}
+
+	resp, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("password"), Args: []string{"anything"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, "RPRT 0", resp.Format())
+	trx.AssertNotCalled(t, "Send")
+}
+
+func TestProxyDoesNotRequireAuthenticationOnUnixSocket(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		rwc:   remoteBuffer{addr: &net.UnixAddr{Name: "/run/rigproxy.sock", Net: "unix"}},
+		trx:   trx,
+		cache: new(nopCache),
+		auth:  NewAuthenticator("secret"),
+	}
+	freq := protocol.Response{Data: []string{"14074000"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Once().Return(freq, nil)
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+
+	require.NoError(t, err)
+	assert.Equal(t, freq, actual)
+}
@@ -0,0 +1,156 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// DeriveFunc derives the responses of multiple commands from one composite response.
+type DeriveFunc func(protocol.Response) (map[protocol.CommandKey]protocol.Response, error)
+
+// FanOut answers a set of cacheable commands from one composite upstream request. The derived responses are put
+// into the cache, so all clients are served from one upstream round trip per cache lifetime. If the rig does not
+// support the composite request, the fan-out disables itself and the commands are forwarded as usual.
+type FanOut struct {
+	request  protocol.Request
+	derive   DeriveFunc
+	keys     map[protocol.CommandKey]*atomic.Bool
+	lock     *sync.Mutex
+	disabled *atomic.Bool
+}
+
+// RigInfoKeys are the commands that can be derived from the response of get_rig_info.
+var RigInfoKeys = []protocol.CommandKey{"get_freq", "get_mode", "get_vfo", "get_split_vfo", "get_ptt"}
+
+// NewFanOut creates a FanOut that answers the given keys with the responses derived from the given command.
+func NewFanOut(command protocol.Command, derive DeriveFunc, keys ...protocol.CommandKey) *FanOut {
+	result := &FanOut{
+		request:  protocol.Request{Command: command},
+		derive:   derive,
+		keys:     make(map[protocol.CommandKey]*atomic.Bool, len(keys)),
+		lock:     new(sync.Mutex),
+		disabled: new(atomic.Bool),
+	}
+	for _, key := range keys {
+		enabled := new(atomic.Bool)
+		enabled.Store(true)
+		result.keys[key] = enabled
+	}
+	return result
+}
+
+// NewRigInfoFanOut creates a FanOut that answers the given keys from get_rig_info. If no keys are given, all
+// RigInfoKeys are used.
+func NewRigInfoFanOut(keys ...protocol.CommandKey) *FanOut {
+	if len(keys) == 0 {
+		keys = RigInfoKeys
+	}
+	return NewFanOut(protocol.LongCommand("get_rig_info"), DeriveFromRigInfo, keys...)
+}
+
+// Handles indicates if the given key is answered by this fan-out.
+func (f *FanOut) Handles(key protocol.CommandKey) bool {
+	if f == nil || f.disabled.Load() {
+		return false
+	}
+	enabled, ok := f.keys[key]
+	return ok && enabled.Load()
+}
+
+// fetch sends the composite request upstream, puts all derived responses into the cache and returns the response
+// for the given key.
+func (f *FanOut) fetch(ctx context.Context, trx Transceiver, cache Cache, key protocol.CommandKey, logger *slog.Logger) (protocol.Response, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// another session may have fetched the composite response in the meantime
+	if resp, ok := cache.Get(key); ok {
+		return resp, true
+	}
+
+	resp, err := trx.Send(ctx, f.request)
+	if err == nil {
+		err = protocol.ResultError(f.request.Key(), resp.Result)
+	}
+	if errors.Is(err, protocol.ErrFeatureNotImplemented) || errors.Is(err, protocol.ErrFeatureNotAvailable) {
+		logger.Warn("fan-out request is not supported, disabling fan-out", "command", f.request.Key(), "error", err)
+		f.disabled.Store(true)
+		return protocol.Response{}, false
+	}
+	if err != nil {
+		logger.Warn("fan-out request failed", "command", f.request.Key(), "error", err)
+		return protocol.Response{}, false
+	}
+
+	derived, err := f.derive(resp)
+	if err != nil {
+		logger.Warn("cannot derive responses from fan-out request, forwarding the command directly", "command", f.request.Key(), "key", key, "error", err)
+		return protocol.Response{}, false
+	}
+
+	if f.request.Cacheable {
+		cache.Put(f.request.Key(), resp)
+	}
+	for derivedKey, derivedResp := range derived {
+		if _, ok := f.keys[derivedKey]; ok {
+			cache.Put(derivedKey, derivedResp)
+		}
+	}
+
+	result, ok := derived[key]
+	if !ok {
+		logger.Info("fan-out response does not contain the command, forwarding it directly", "command", f.request.Key(), "missing", key)
+		f.keys[key].Store(false)
+	}
+	return result, ok
+}
+
+// DeriveFromRigInfo derives the responses of get_freq, get_mode, get_vfo and get_split_vfo from the response of
+// get_rig_info. The response of get_ptt is only derived if the rig info contains the PTT state.
+func DeriveFromRigInfo(resp protocol.Response) (map[protocol.CommandKey]protocol.Response, error) {
+	info, err := protocol.ParseRigInfo(resp)
+	if err != nil {
+		return nil, err
+	}
+	rx, ok := info.RXVFO()
+	if !ok {
+		rx = info.VFOs[0]
+	}
+	tx, ok := info.TXVFO()
+	if !ok {
+		tx = rx
+	}
+
+	result := map[protocol.CommandKey]protocol.Response{
+		"get_freq":      protocol.GetFreqResponse(rx.Frequency),
+		"get_mode":      protocol.GetModeResponse(rx.Mode, rx.Width),
+		"get_vfo":       protocol.GetVFOResponse(rx.Name),
+		"get_split_vfo": protocol.GetSplitVFOResponse(info.Split, tx.Name),
+	}
+	if info.HasPTT {
+		result["get_ptt"] = protocol.GetPTTResponse(info.PTT)
+	}
+	return result, nil
+}
+
+// UseFanOut answers the requests handled by the given FanOut from one composite request that is sent through the
+// next handler. The derived responses are put into the given cache.
+func UseFanOut(fanOut *FanOut, cache Cache) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			if req.Cacheable && fanOut.Handles(req.Key()) {
+				resp, ok := fanOut.fetch(ctx, handlerTransceiver{next}, cache, req.Key(), loggerFromContext(ctx))
+				if ok {
+					setSource(ctx, SourceFanOut)
+					return resp, nil
+				}
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+}
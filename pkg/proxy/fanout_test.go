@@ -0,0 +1,148 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+var rigInfoResponse = protocol.Response{
+	Data: []string{
+		"VFO=VFOA Freq=14074000 Mode=USB Width=3000 RX=1 TX=0",
+		"VFO=VFOB Freq=14076000 Mode=USB Width=3000 RX=0 TX=1",
+		"Split=1 SatMode=0",
+	},
+	Result: "0",
+}
+
+func isRequest(command string) interface{} {
+	return mock.MatchedBy(func(req protocol.Request) bool {
+		return req.Long == command
+	})
+}
+
+func TestFanOutAnswersFromOneRequest(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:    trx,
+		cache:  cache.New(),
+		fanOut: NewRigInfoFanOut(),
+	}
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Once().Return(rigInfoResponse, nil)
+
+	freq, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	mode, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_mode")})
+	require.NoError(t, err)
+	split, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_split_vfo")})
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.GetFreqResponse(14074000), freq)
+	assert.Equal(t, protocol.GetModeResponse("USB", 3000), mode)
+	assert.Equal(t, protocol.GetSplitVFOResponse(true, "VFOB"), split)
+	trx.AssertExpectations(t)
+}
+
+func TestFanOutForwardsKeysMissingInResponse(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:    trx,
+		cache:  cache.New(),
+		fanOut: NewRigInfoFanOut(),
+	}
+	ptt := protocol.Response{Data: []string{"0"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Once().Return(rigInfoResponse, nil)
+	trx.On("Send", mock.Anything, isRequest("get_ptt")).Once().Return(ptt, nil)
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_ptt")})
+	require.NoError(t, err)
+
+	assert.Equal(t, ptt, actual)
+	assert.False(t, proxy.fanOut.Handles("get_ptt"))
+	assert.True(t, proxy.fanOut.Handles("get_freq"))
+	trx.AssertExpectations(t)
+}
+
+func TestFanOutDisablesItselfWhenNotSupported(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:    trx,
+		cache:  cache.New(),
+		fanOut: NewRigInfoFanOut(),
+	}
+	freq := protocol.Response{Data: []string{"14074000"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Once().Return(protocol.Response{Result: "-11"}, nil)
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Once().Return(freq, nil)
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+
+	assert.Equal(t, freq, actual)
+	assert.False(t, proxy.fanOut.Handles("get_freq"))
+	trx.AssertExpectations(t)
+}
+
+func TestFanOutForwardsRequestWhenDerivingFails(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:    trx,
+		cache:  cache.New(),
+		fanOut: NewRigInfoFanOut(),
+	}
+	freq := protocol.Response{Data: []string{"14074000"}, Result: "0"}
+	invalid := protocol.Response{Data: []string{"VFO=VFOA Freq=invalid Mode=USB Width=3000 RX=1 TX=1"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Once().Return(invalid, nil)
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Once().Return(freq, nil)
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+
+	assert.Equal(t, freq, actual)
+	assert.True(t, proxy.fanOut.Handles("get_freq"), "the fan-out stays enabled")
+	trx.AssertExpectations(t)
+}
+
+func TestFanOutFetchesRigInfoAgainAfterVFOOperation(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:    trx,
+		cache:  cache.New(),
+		fanOut: NewRigInfoFanOut(),
+	}
+	exchanged := protocol.Response{
+		Data: []string{
+			"VFO=VFOA Freq=14076000 Mode=CW Width=500 RX=1 TX=0",
+			"VFO=VFOB Freq=14074000 Mode=USB Width=3000 RX=0 TX=1",
+			"Split=1 SatMode=0",
+		},
+		Result: "0",
+	}
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Once().Return(rigInfoResponse, nil)
+	trx.On("Send", mock.Anything, isRequest("vfo_op")).Once().Return(protocol.Response{Result: "0"}, nil)
+	trx.On("Send", mock.Anything, isRequest("get_rig_info")).Return(exchanged, nil)
+
+	freq, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.GetFreqResponse(14074000), freq)
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_mode")})
+	require.NoError(t, err)
+
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("vfo_op"), Args: []string{"XCHG"}})
+	require.NoError(t, err)
+
+	info, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_rig_info")})
+	require.NoError(t, err)
+	assert.Equal(t, exchanged.Data, info.Data)
+	mode, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_mode")})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.GetModeResponse("CW", 500), mode)
+	freq, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	assert.Equal(t, protocol.GetFreqResponse(14076000), freq)
+	trx.AssertExpectations(t)
+}
@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// Handler handles a request of a client.
+type Handler interface {
+	Handle(context.Context, protocol.Request) (protocol.Response, error)
+}
+
+// HandlerFunc is a function that implements the Handler interface.
+type HandlerFunc func(context.Context, protocol.Request) (protocol.Response, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+	return f(ctx, req)
+}
+
+// Middleware wraps the given Handler to intercept or rewrite the requests and responses. A middleware may answer a
+// request itself without calling the next handler.
+type Middleware func(next Handler) Handler
+
+func chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// handlerTransceiver lets a Handler be used where a Transceiver is expected.
+type handlerTransceiver struct {
+	handler Handler
+}
+
+func (t handlerTransceiver) Send(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+	return t.handler.Handle(ctx, req)
+}
+
+type requestStateKey struct{}
+
+// requestState is passed through the context of a request to the middleware chain.
+type requestState struct {
+	session *Proxy
+	source  Source
+}
+
+func withRequestState(ctx context.Context, session *Proxy) (context.Context, *requestState) {
+	state := &requestState{session: session, source: SourceLocal}
+	return context.WithValue(ctx, requestStateKey{}, state), state
+}
+
+func requestStateFromContext(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+func setSource(ctx context.Context, source Source) {
+	if state := requestStateFromContext(ctx); state != nil {
+		state.source = source
+	}
+}
+
+func sessionFromContext(ctx context.Context) *Proxy {
+	if state := requestStateFromContext(ctx); state != nil {
+		return state.session
+	}
+	return nil
+}
+
+// SessionFromContext returns the information about the client session that sent the request that is handled with
+// the given context.
+func SessionFromContext(ctx context.Context) (SessionInfo, bool) {
+	session := sessionFromContext(ctx)
+	if session == nil {
+		return SessionInfo{}, false
+	}
+	return session.Info(), true
+}
+
+func remoteAddrFromContext(ctx context.Context) net.Addr {
+	if session := sessionFromContext(ctx); session != nil {
+		return session.remoteAddr()
+	}
+	return nil
+}
+
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if session := sessionFromContext(ctx); session != nil {
+		return session.log()
+	}
+	return slog.Default()
+}
+
+// AnswerChkVfo answers the chk_vfo command locally.
+func AnswerChkVfo() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			if req.Key() == protocol.CommandKey("chk_vfo") {
+				return ChkVfoResponse, nil
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+// UseCache answers cacheable requests from the given cache and puts the successful responses of the next handler into
+// the cache. Requests that change the state of the rig invalidate the affected entries.
+func UseCache(cache Cache) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			if req.InvalidatesAll {
+				if flusher, ok := cache.(Flusher); ok {
+					flusher.Flush()
+				}
+			} else {
+				for _, key := range req.InvalidatedKeys() {
+					cache.Invalidate(key)
+				}
+			}
+
+			if !req.Cacheable {
+				return next.Handle(ctx, req)
+			}
+
+			if resp, ok := cache.Get(req.Key()); ok {
+				setSource(ctx, SourceCache)
+				return resp, nil
+			}
+			resp, err := next.Handle(ctx, req)
+			if err != nil {
+				return protocol.Response{}, err
+			}
+			if resp.Result == "0" {
+				cache.Put(req.Key(), resp)
+			}
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func recordCalls(name string, calls *[]string) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			*calls = append(*calls, name)
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+func TestChainCallsMiddlewareInOrder(t *testing.T) {
+	var calls []string
+	handler := chain(HandlerFunc(func(context.Context, protocol.Request) (protocol.Response, error) {
+		calls = append(calls, "handler")
+		return protocol.Response{Result: "0"}, nil
+	}), []Middleware{recordCalls("first", &calls), recordCalls("second", &calls)})
+
+	_, err := handler.Handle(context.Background(), protocol.Request{Command: protocol.LongCommand("get_freq")})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestWithChainReplacesDefaultChain(t *testing.T) {
+	trx := new(mockTransceiver)
+	freq := protocol.Response{Data: []string{"14074000"}, Result: "0"}
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Twice().Return(freq, nil)
+	rejectSetFreq := func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			if req.Key() == protocol.CommandKey("set_freq") {
+				return protocol.Response{}, fmt.Errorf("%w: frequency is locked", protocol.ErrSecurityError)
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+	var calls []string
+	proxy := Proxy{
+		trx:   trx,
+		cache: cache.New(),
+	}
+	WithChain(rejectSetFreq)(&proxy)
+	WithMiddleware(recordCalls("extension", &calls))(&proxy)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_freq"), Args: []string{"7074000"}})
+	assert.ErrorIs(t, err, protocol.ErrSecurityError)
+	for range 2 {
+		actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+		require.NoError(t, err)
+		assert.Equal(t, freq, actual)
+	}
+
+	assert.Equal(t, []string{"extension", "extension"}, calls, "the custom chain does not use the cache")
+	trx.AssertExpectations(t)
+}
+
+func TestSessionFromContext(t *testing.T) {
+	var actual SessionInfo
+	var ok bool
+	proxy := Proxy{
+		rwc:   remoteBuffer{addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.10"), Port: 50312}},
+		cache: new(nopCache),
+	}
+	proxy.session.id = 7
+	WithMiddleware(func(Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			actual, ok = SessionFromContext(ctx)
+			return protocol.Response{Result: "0"}, nil
+		})
+	})(&proxy)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(7), actual.ID)
+	assert.Equal(t, "192.168.1.10:50312", actual.RemoteAddr)
+
+	_, ok = SessionFromContext(context.Background())
+	assert.False(t, ok)
+}
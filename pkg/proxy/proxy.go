@@ -4,17 +4,40 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ftl/rigproxy/pkg/protocol"
 )
 
 type Proxy struct {
-	rwc    io.ReadWriteCloser
-	trx    Transceiver
-	cache  Cache
-	closed chan struct{}
-	trace  bool
+	rwc          io.ReadWriteCloser
+	trx          Transceiver
+	cache        Cache
+	fanOut       *FanOut
+	transverter  *Transverter
+	txGuard      *TXGuard
+	acl          *SharedACL
+	auth         *Authenticator
+	readRequests func(io.Reader) protocol.RequestReader
+	closed       chan struct{}
+	closeOnce    sync.Once
+	trace        atomic.Bool
+	registry     *Registry
+	session      sessionState
+	logger       *slog.Logger
+	done         <-chan struct{}
+	timeout      time.Duration
+	metrics      Metrics
+	chain        []Middleware
+	middleware   []Middleware
+	handler      Handler
+
+	authenticated bool
 }
 
 type Transceiver interface {
@@ -27,6 +50,12 @@ type Cache interface {
 	Invalidate(protocol.CommandKey)
 }
 
+// Flusher is implemented by a Cache that can remove all of its entries at once. Requests that invalidate the whole
+// state of the rig, e.g. raw CAT commands, flush the cache if it implements Flusher.
+type Flusher interface {
+	Flush()
+}
+
 var ChkVfoResponse = protocol.Response{
 	Command: protocol.CommandKey("chk_vfo"),
 	Data:    []string{"CHKVFO 0"},
@@ -34,23 +63,170 @@ var ChkVfoResponse = protocol.Response{
 	Result:  "0",
 }
 
-func New(rwc io.ReadWriteCloser, trx Transceiver, done <-chan struct{}, trace bool) *Proxy {
-	return NewCached(rwc, trx, new(nopCache), done, trace)
+// Source describes where the response to a request came from.
+type Source string
+
+// The sources of the response to a request.
+const (
+	SourceLocal    Source = "local"
+	SourceCache    Source = "cache"
+	SourceFanOut   Source = "fan-out"
+	SourceUpstream Source = "upstream"
+)
+
+// Metrics receives the outcome of every request that is handled by a Proxy.
+type Metrics interface {
+	ObserveRequest(command protocol.CommandKey, source Source, latency time.Duration, err error)
+}
+
+// Option configures optional features of a Proxy.
+type Option func(*Proxy)
+
+// WithCache answers the cacheable requests from the given cache. Without cache, every request is forwarded to the
+// upstream server.
+func WithCache(cache Cache) Option {
+	return func(p *Proxy) {
+		p.cache = cache
+	}
+}
+
+// WithDone closes the client connection when the given channel is closed.
+func WithDone(done <-chan struct{}) Option {
+	return func(p *Proxy) {
+		p.done = done
+	}
+}
+
+// WithTrace enables or disables tracing of the communication of the session.
+func WithTrace(trace bool) Option {
+	return func(p *Proxy) {
+		p.trace.Store(trace)
+	}
+}
+
+// WithRotatorProtocol reads the requests of the client using the rotctld protocol.
+func WithRotatorProtocol() Option {
+	return func(p *Proxy) {
+		p.readRequests = protocol.NewRotatorRequestReader
+	}
+}
+
+// WithRequestTimeout limits the time to wait for the upstream server to answer a request.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(p *Proxy) {
+		p.timeout = timeout
+	}
+}
+
+// WithMetrics reports the source, the latency and the error of every request to the given Metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(p *Proxy) {
+		p.metrics = metrics
+	}
+}
+
+// WithMiddleware adds the given middleware to the end of the chain, right before the requests are forwarded to the
+// upstream server. With the default chain, the middleware is called for every request that is not answered locally
+// or from the cache. The first middleware is the outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(p *Proxy) {
+		p.middleware = append(p.middleware, middleware...)
+	}
+}
+
+// WithChain replaces the default chain of the Proxy with the given middleware. Use CheckACL, RequireAuthentication,
+// AnswerChkVfo, UseCache and UseFanOut to compose the built-in features with your own middleware. The middleware
+// added with WithMiddleware is still appended to the given chain.
+func WithChain(middleware ...Middleware) Option {
+	return func(p *Proxy) {
+		p.chain = middleware
+	}
+}
+
+// WithFanOut answers the commands handled by the given FanOut from one composite upstream request.
+func WithFanOut(fanOut *FanOut) Option {
+	return func(p *Proxy) {
+		p.fanOut = fanOut
+	}
+}
+
+// WithTransverter lets the clients see the on-air frequencies of the given Transverter instead of the frequencies of
+// the rig.
+func WithTransverter(transverter *Transverter) Option {
+	return func(p *Proxy) {
+		p.transverter = transverter
+	}
+}
+
+// WithTXGuard refuses transmissions that are not allowed by the given TXGuard.
+func WithTXGuard(guard *TXGuard) Option {
+	return func(p *Proxy) {
+		p.txGuard = guard
+	}
+}
+
+// WithACL restricts the access of the client according to the ACL that is currently held by the given SharedACL.
+func WithACL(acl *SharedACL) Option {
+	return func(p *Proxy) {
+		p.acl = acl
+	}
+}
+
+// WithLogger lets the Proxy use the given logger. The log records of the Proxy contain the session id and the address
+// of the client. Without logger, slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *Proxy) {
+		p.logger = logger
+	}
+}
+
+// WithAuthenticator requires the client to authenticate with the password command before any other command is
+// accepted. Requests of unauthenticated clients are rejected with a security error. Clients that are connected
+// through a Unix domain socket do not need to authenticate.
+func WithAuthenticator(auth *Authenticator) Option {
+	return func(p *Proxy) {
+		p.auth = auth
+	}
+}
+
+func New(rwc io.ReadWriteCloser, trx Transceiver, done <-chan struct{}, trace bool, opts ...Option) *Proxy {
+	return Start(rwc, trx, append([]Option{WithDone(done), WithTrace(trace)}, opts...)...)
+}
+
+func NewCached(rwc io.ReadWriteCloser, trx Transceiver, cache Cache, done <-chan struct{}, trace bool, opts ...Option) *Proxy {
+	return Start(rwc, trx, append([]Option{WithCache(cache), WithDone(done), WithTrace(trace)}, opts...)...)
+}
+
+// NewCachedWithFanOut creates a new caching proxy that answers the commands handled by the given FanOut from one
+// composite upstream request.
+func NewCachedWithFanOut(rwc io.ReadWriteCloser, trx Transceiver, cache Cache, fanOut *FanOut, done <-chan struct{}, trace bool) *Proxy {
+	return NewCached(rwc, trx, cache, done, trace, WithFanOut(fanOut))
 }
 
-func NewCached(rwc io.ReadWriteCloser, trx Transceiver, cache Cache, done <-chan struct{}, trace bool) *Proxy {
+func NewRotator(rwc io.ReadWriteCloser, trx Transceiver, cache Cache, done <-chan struct{}, trace bool, opts ...Option) *Proxy {
+	return Start(rwc, trx, append([]Option{WithCache(cache), WithDone(done), WithTrace(trace), WithRotatorProtocol()}, opts...)...)
+}
+
+// Start starts a new proxy session that handles the requests of the given client connection and forwards them to
+// the given transceiver. Without options, the requests are read using the rigctld protocol and nothing is cached.
+func Start(rwc io.ReadWriteCloser, trx Transceiver, opts ...Option) *Proxy {
 	result := Proxy{
-		rwc:    rwc,
-		trx:    trx,
-		cache:  cache,
-		closed: make(chan struct{}),
-		trace:  trace,
+		rwc:          rwc,
+		trx:          trx,
+		cache:        new(nopCache),
+		readRequests: protocol.NewRequestReader,
+		closed:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&result)
 	}
+	result.registry.register(&result)
+	result.logger = result.sessionLogger()
 
 	go result.start()
 	go func() {
 		select {
-		case <-done:
+		case <-result.done:
 			result.Close()
 			rwc.Close()
 		case <-result.closed:
@@ -63,25 +239,24 @@ func NewCached(rwc io.ReadWriteCloser, trx Transceiver, cache Cache, done <-chan
 
 func (p *Proxy) start() {
 	defer p.rwc.Close()
-	r := protocol.NewRequestReader(p.rwc)
+	r := p.readRequests(p.rwc)
 	for {
 		req, err := r.ReadRequest()
 		if err == io.EOF {
-			log.Println("eof:", err)
+			p.log().Info("client disconnected")
 			p.Close()
 			return
 		}
 		if err != nil {
-			log.Println("proxy:", err)
+			p.log().Warn("cannot read request", "error", err)
 			p.Close()
 			return
 		}
 
 		resp, err := p.handleRequest(req)
 		if err != nil {
-			log.Println("request:", err)
-			p.Close()
-			return
+			p.log().Warn("request failed", "command", req.Key(), "error", err)
+			resp = protocol.ErrorResponse(protocol.CommandKey(req.Long), err)
 		}
 
 		if req.ExtendedSeparator != "" {
@@ -93,56 +268,155 @@ func (p *Proxy) start() {
 }
 
 func (p *Proxy) handleRequest(req protocol.Request) (protocol.Response, error) {
-	p.traceLog(">", req.LongFormat())
+	p.session.record(req.Key())
+	startTime := time.Now()
 
-	if req.Key() == protocol.CommandKey("chk_vfo") {
-		p.traceLog("<", "CHKVFO 0")
-		return ChkVfoResponse, nil
+	ctx, state := withRequestState(context.Background(), p)
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
 	}
+	resp, err := p.requestHandler().Handle(ctx, req)
 
-	if req.InvalidatesCommand != "" {
-		p.cache.Invalidate(req.InvalidatedKey())
+	latency := time.Since(startTime)
+	if p.metrics != nil {
+		p.metrics.ObserveRequest(req.Key(), state.source, latency, err)
 	}
+	p.traceRequest(req, resp, state.source, err, latency)
+	return resp, err
+}
 
-	if req.Cacheable {
-		resp, ok := p.cache.Get(req.Key())
-		if ok {
-			p.traceLog("c", resp.Format())
-			return resp, nil
-		}
+// requestHandler returns the middleware chain that handles the requests of this session. The chain is built on first
+// use, to support proxies that are not created with Start.
+func (p *Proxy) requestHandler() Handler {
+	if p.handler != nil {
+		return p.handler
 	}
 
-	resp, err := p.trx.Send(context.Background(), req)
-	if err != nil {
-		return protocol.Response{}, err
+	middleware := p.chain
+	if middleware == nil {
+		middleware = p.defaultChain()
+	}
+	middleware = append(middleware[:len(middleware):len(middleware)], p.middleware...)
+	p.handler = chain(HandlerFunc(p.forward), middleware)
+	return p.handler
+}
+
+// defaultChain returns the built-in middleware in the order of processing: access control, authentication, local
+// commands, transmit guard, frequency mapping, cache and fan-out. The transmit guard checks the on-air frequencies.
+// The cache contains the frequencies of the rig, this way the selection of the transverter band can change at any
+// time.
+func (p *Proxy) defaultChain() []Middleware {
+	result := []Middleware{
+		CheckACL(p.acl),
+		RequireAuthentication(p.auth),
+		AnswerChkVfo(),
+	}
+	if p.txGuard != nil {
+		result = append(result, GuardTransmission(p.txGuard))
+	}
+	if p.transverter != nil {
+		result = append(result, MapFrequencies(p.transverter))
+	}
+	if p.cache != nil {
+		result = append(result, UseCache(p.cache))
+		if p.fanOut != nil {
+			result = append(result, UseFanOut(p.fanOut, p.cache))
+		}
 	}
+	return result
+}
+
+// forward sends the request to the upstream server, it is the end of the middleware chain.
+func (p *Proxy) forward(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+	setSource(ctx, SourceUpstream)
+	return p.trx.Send(ctx, req)
+}
 
-	if req.Cacheable {
-		p.cache.Put(req.Key(), resp)
+func (p *Proxy) remoteAddr() net.Addr {
+	conn, ok := p.rwc.(interface{ RemoteAddr() net.Addr })
+	if !ok {
+		return nil
 	}
+	return conn.RemoteAddr()
+}
 
-	p.traceLog("<", resp.Format())
-	return resp, nil
+func isUnixAddr(addr net.Addr) bool {
+	_, ok := addr.(*net.UnixAddr)
+	return ok
 }
 
+// Close stops this proxy session. It is safe to call Close concurrently and multiple times.
 func (p *Proxy) Close() {
-	select {
-	case <-p.closed:
-		return
-	default:
+	p.closeOnce.Do(func() {
 		close(p.closed)
-	}
+		p.registry.unregister(p)
+	})
 }
 
 func (p *Proxy) Wait() {
 	<-p.closed
 }
 
-func (p *Proxy) traceLog(v ...interface{}) {
-	if !p.trace {
+// SetTrace enables or disables tracing of the communication of this session.
+func (p *Proxy) SetTrace(trace bool) {
+	p.trace.Store(trace)
+}
+
+// traceRequest logs the given request and its response, if tracing is enabled for this session. The password is
+// never logged.
+func (p *Proxy) traceRequest(req protocol.Request, resp protocol.Response, source Source, err error, latency time.Duration) {
+	if !p.trace.Load() {
 		return
 	}
-	log.Print(v...)
+
+	request := req.LongFormat()
+	if req.Key() == protocol.CommandKey("password") {
+		request = "\\password ***"
+	}
+	result := resp.Result
+	if err != nil {
+		result = strconv.Itoa(protocol.ErrorCode(err))
+	}
+
+	p.log().Info("request",
+		"command", req.Key(),
+		"request", request,
+		"source", source,
+		"cache_hit", source == SourceCache || source == SourceFanOut,
+		"latency", latency,
+		"result", result,
+		"response", resp.Format(),
+	)
+}
+
+// log returns the logger of this session. Without logger, slog.Default() is used.
+func (p *Proxy) log() *slog.Logger {
+	if p.logger == nil {
+		return slog.Default()
+	}
+	return p.logger
+}
+
+// sessionLogger returns a logger that adds the session id, the upstream and the address of the client to the log
+// records. It is created once when the session starts.
+func (p *Proxy) sessionLogger() *slog.Logger {
+	info := p.Info()
+	var attrs []any
+	if info.ID != 0 {
+		attrs = append(attrs, "session", info.ID)
+	}
+	if info.Upstream != "" {
+		attrs = append(attrs, "upstream", info.Upstream)
+	}
+	if info.RemoteAddr != "" {
+		attrs = append(attrs, "client", info.RemoteAddr)
+	}
+	if len(attrs) == 0 {
+		return p.log()
+	}
+	return p.log().With(attrs...)
 }
 
 type nopCache struct{}
@@ -1,15 +1,18 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/ftl/rigproxy/pkg/cache"
 	"github.com/ftl/rigproxy/pkg/protocol"
 	"github.com/ftl/rigproxy/pkg/test"
 )
@@ -56,6 +59,20 @@ func TestCommands(t *testing.T) {
 	}
 }
 
+func TestRotatorProxyCachesPosition(t *testing.T) {
+	trxBuffer := test.NewBuffer("get_pos:\nAzimuth: 180.000000\nElevation: 10.000000\nRPRT 0\nset_pos: 90 0\nRPRT 0\nget_pos:\nAzimuth: 90.000000\nElevation: 0.000000\nRPRT 0\n")
+	trx := protocol.NewTransceiver(trxBuffer)
+	defer trx.Close()
+
+	proxyBuffer := test.NewBuffer("p\np\nP 90 0\np\n")
+	proxy := NewRotator(proxyBuffer, trx, cache.New(), nil, false)
+	defer proxy.Close()
+	proxy.Wait()
+
+	trxBuffer.AssertWritten(t, "+\\get_pos\n+\\set_pos 90 0\n+\\get_pos\n")
+	proxyBuffer.AssertWritten(t, "180.000000\n10.000000\n180.000000\n10.000000\nRPRT 0\n90.000000\n0.000000\n")
+}
+
 func TestProxyStopsWhenDone(t *testing.T) {
 	done := make(chan struct{})
 	bytes := make(chan byte)
@@ -80,6 +97,17 @@ func TestProxyStopsWhenReceiveFails(t *testing.T) {
 	proxy.Wait()
 }
 
+func TestProxyReportsErrorsAsResult(t *testing.T) {
+	trx := new(mockTransceiver)
+	trx.On("Send", mock.Anything, mock.Anything).Once().Return(protocol.Response{}, context.DeadlineExceeded)
+	proxyBuffer := test.NewBuffer("f\n")
+	proxy := New(proxyBuffer, trx, nil, false)
+
+	proxy.Wait()
+
+	proxyBuffer.AssertWritten(t, "RPRT -5\n")
+}
+
 func TestProxyInvalidatesCache(t *testing.T) {
 	trx := new(mockTransceiver)
 	cache := new(mockCache)
@@ -98,6 +126,27 @@ func TestProxyInvalidatesCache(t *testing.T) {
 	})
 }
 
+func TestProxyFlushesCacheOnRawCommand(t *testing.T) {
+	trx := new(mockTransceiver)
+	cache := new(mockCache)
+	proxy := Proxy{
+		trx:   trx,
+		cache: cache,
+	}
+
+	cache.On("Flush").Once()
+	trx.On("Send", mock.Anything, mock.Anything).Once().Return(protocol.Response{Data: []string{"FA00014074000;"}, Result: "0"}, nil)
+
+	_, err := proxy.handleRequest(protocol.Request{
+		Command: protocol.LongCommand("send_cmd"),
+		Args:    []string{"FA;"},
+	})
+
+	assert.NoError(t, err)
+	cache.AssertExpectations(t)
+	trx.AssertExpectations(t)
+}
+
 func TestProxyUsesCache(t *testing.T) {
 	cache := new(mockCache)
 	proxy := Proxy{
@@ -150,6 +199,109 @@ func TestProxyFillsCache(t *testing.T) {
 	cache.AssertExpectations(t)
 }
 
+func TestProxyDoesNotCacheErrors(t *testing.T) {
+	trx := new(mockTransceiver)
+	cache := new(mockCache)
+	proxy := Proxy{
+		trx:   trx,
+		cache: cache,
+	}
+	cachedCommand := protocol.CommandKey("i_am_cached")
+	resp := protocol.Response{
+		Command: cachedCommand,
+		Result:  "-5",
+	}
+
+	cache.On("Get", cachedCommand).Once().Return(protocol.Response{}, false)
+	trx.On("Send", mock.Anything, mock.Anything).Once().Return(resp, nil)
+
+	proxy.handleRequest(protocol.Request{
+		Command: protocol.Command{
+			Long:      string(cachedCommand),
+			Cacheable: true,
+		},
+	})
+
+	cache.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+	cache.AssertExpectations(t)
+}
+
+func TestProxyTracesRequests(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "latency" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	c := cache.New()
+	c.Put("get_freq", protocol.Response{Data: []string{"14074000"}, Result: "0"})
+	proxy := Proxy{
+		cache:  c,
+		logger: logger,
+	}
+	proxy.session.id = 7
+	proxy.logger = proxy.sessionLogger()
+	proxy.SetTrace(true)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	assert.NoError(t, err)
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("password"), Args: []string{"secret"}})
+	assert.NoError(t, err)
+
+	expected := `level=INFO msg=request session=7 command=get_freq request=\get_freq source=cache cache_hit=true result=0 response=14074000
+level=INFO msg=request session=7 command=password request="\\password ***" source=local cache_hit=false result=0 response="RPRT 0"
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestStartWithOptions(t *testing.T) {
+	trxBuffer := test.NewBuffer("get_freq:\n14074000\nRPRT 0\n")
+	trx := protocol.NewTransceiver(trxBuffer)
+	defer trx.Close()
+
+	var forwarded []protocol.CommandKey
+	recordForwarded := func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			forwarded = append(forwarded, req.Key())
+			return next.Handle(ctx, req)
+		})
+	}
+	metrics := new(recordingMetrics)
+
+	proxyBuffer := test.NewBuffer("f\nf\n\\chk_vfo\n")
+	proxy := Start(proxyBuffer, trx,
+		WithCache(cache.New()),
+		WithRequestTimeout(time.Second),
+		WithMiddleware(recordForwarded),
+		WithMetrics(metrics),
+	)
+	defer proxy.Close()
+	proxy.Wait()
+
+	trxBuffer.AssertWritten(t, "+\\get_freq\n")
+	proxyBuffer.AssertWritten(t, "14074000\n14074000\nCHKVFO 0\n")
+	assert.Equal(t, []protocol.CommandKey{"get_freq"}, forwarded)
+	assert.Equal(t, recordingMetrics{
+		{"get_freq", SourceUpstream},
+		{"get_freq", SourceCache},
+		{"chk_vfo", SourceLocal},
+	}, *metrics)
+}
+
+type recordedRequest struct {
+	command protocol.CommandKey
+	source  Source
+}
+
+type recordingMetrics []recordedRequest
+
+func (m *recordingMetrics) ObserveRequest(command protocol.CommandKey, source Source, _ time.Duration, _ error) {
+	*m = append(*m, recordedRequest{command, source})
+}
+
 type mockCache struct {
 	mock.Mock
 }
@@ -167,6 +319,10 @@ func (m *mockCache) Invalidate(key protocol.CommandKey) {
 	m.Called(key)
 }
 
+func (m *mockCache) Flush() {
+	m.Called()
+}
+
 type mockTransceiver struct {
 	mock.Mock
 }
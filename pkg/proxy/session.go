@@ -0,0 +1,167 @@
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// SessionInfo describes the client session of a Proxy.
+type SessionInfo struct {
+	ID          uint64
+	Upstream    string
+	RemoteAddr  string
+	Connected   time.Time
+	Requests    uint64
+	LastCommand protocol.CommandKey
+	LastRequest time.Time
+	Trace       bool
+}
+
+// sessionState keeps track of the requests of a client session. The zero value is ready to use.
+type sessionState struct {
+	lock        sync.Mutex
+	id          uint64
+	upstream    string
+	connected   time.Time
+	requests    uint64
+	lastCommand protocol.CommandKey
+	lastRequest time.Time
+}
+
+func (s *sessionState) record(key protocol.CommandKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests++
+	s.lastCommand = key
+	s.lastRequest = time.Now()
+}
+
+// Info returns the current state of the client session of this proxy.
+func (p *Proxy) Info() SessionInfo {
+	p.session.lock.Lock()
+	defer p.session.lock.Unlock()
+
+	result := SessionInfo{
+		ID:          p.session.id,
+		Upstream:    p.session.upstream,
+		Connected:   p.session.connected,
+		Requests:    p.session.requests,
+		LastCommand: p.session.lastCommand,
+		LastRequest: p.session.lastRequest,
+		Trace:       p.trace.Load(),
+	}
+	if addr := p.remoteAddr(); addr != nil {
+		result.RemoteAddr = addr.String()
+	}
+	return result
+}
+
+// WithRegistry registers the client session in the given registry as session of the given upstream.
+func WithRegistry(registry *Registry, upstream string) Option {
+	return func(p *Proxy) {
+		p.registry = registry
+		p.session.upstream = upstream
+	}
+}
+
+// Registry keeps track of all running client sessions.
+type Registry struct {
+	lock     *sync.Mutex
+	nextID   uint64
+	sessions map[uint64]*Proxy
+}
+
+// NewRegistry creates a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		lock:     new(sync.Mutex),
+		sessions: make(map[uint64]*Proxy),
+	}
+}
+
+func (r *Registry) register(p *Proxy) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.nextID++
+	p.session.lock.Lock()
+	p.session.id = r.nextID
+	p.session.connected = time.Now()
+	p.session.lock.Unlock()
+	r.sessions[r.nextID] = p
+}
+
+func (r *Registry) unregister(p *Proxy) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.sessions, p.Info().ID)
+}
+
+// Sessions returns the information about all running client sessions, ordered by their ID.
+func (r *Registry) Sessions() []SessionInfo {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result := make([]SessionInfo, 0, len(r.sessions))
+	for _, p := range r.sessions {
+		result = append(result, p.Info())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Session returns the proxy of the client session with the given ID.
+func (r *Registry) Session(id uint64) (*Proxy, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	result, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("no session with id %d", id)
+	}
+	return result, nil
+}
+
+// Kick disconnects the client session with the given ID.
+func (r *Registry) Kick(id uint64) error {
+	p, err := r.Session(id)
+	if err != nil {
+		return err
+	}
+	p.Close()
+	return nil
+}
+
+// SetTrace enables or disables tracing for the client session with the given ID.
+func (r *Registry) SetTrace(id uint64, trace bool) error {
+	p, err := r.Session(id)
+	if err != nil {
+		return err
+	}
+	p.SetTrace(trace)
+	return nil
+}
+
+// SetTraceAll enables or disables tracing for all running client sessions.
+func (r *Registry) SetTraceAll(trace bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, p := range r.sessions {
+		p.SetTrace(trace)
+	}
+}
@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	trx := new(mockTransceiver)
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Return(protocol.Response{Data: []string{"14074000"}, Result: "0"}, nil)
+	proxy := &Proxy{
+		trx:    trx,
+		cache:  new(nopCache),
+		closed: make(chan struct{}),
+	}
+	WithRegistry(registry, "rig")(proxy)
+	registry.register(proxy)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+
+	sessions := registry.Sessions()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, uint64(1), sessions[0].ID)
+	assert.Equal(t, "rig", sessions[0].Upstream)
+	assert.Equal(t, uint64(2), sessions[0].Requests)
+	assert.Equal(t, protocol.CommandKey("get_freq"), sessions[0].LastCommand)
+	assert.False(t, sessions[0].Trace)
+
+	require.NoError(t, registry.SetTrace(1, true))
+	assert.True(t, registry.Sessions()[0].Trace)
+	registry.SetTraceAll(false)
+	assert.False(t, registry.Sessions()[0].Trace)
+
+	require.NoError(t, registry.Kick(1))
+	assert.Empty(t, registry.Sessions())
+	select {
+	case <-proxy.closed:
+	default:
+		assert.Fail(t, "proxy not closed")
+	}
+
+	assert.Error(t, registry.Kick(1))
+	assert.Error(t, registry.SetTrace(1, false))
+}
+
+func TestConcurrentCloseAndKick(t *testing.T) {
+	registry := NewRegistry()
+	proxy := &Proxy{
+		cache:  new(nopCache),
+		closed: make(chan struct{}),
+	}
+	WithRegistry(registry, "rig")(proxy)
+	registry.register(proxy)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			proxy.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			registry.Kick(1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, registry.Sessions())
+}
@@ -0,0 +1,276 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// FrequencyMapping maps the frequency range of the rig to the on-air frequency range of a transverter. All
+// frequencies are in Hz, the on-air frequency is the rig frequency plus the offset.
+type FrequencyMapping struct {
+	Name   string
+	From   int
+	To     int
+	Offset int
+	// BandSwitch indicates that the mapping is only active while it is selected.
+	BandSwitch bool
+}
+
+func (m FrequencyMapping) rigContains(frequency int) bool {
+	return m.From <= frequency && frequency <= m.To
+}
+
+func (m FrequencyMapping) airContains(frequency int) bool {
+	return m.From+m.Offset <= frequency && frequency <= m.To+m.Offset
+}
+
+// Transverter translates between the frequencies of the rig and the on-air frequencies according to a set of
+// frequency mappings. Mappings without band switch are always active. Mappings with band switch are only active while
+// they are selected, a mapping is selected when a client tunes into its on-air range or by calling Select. Tuning to a
+// frequency outside of the selected on-air range deselects the mapping.
+type Transverter struct {
+	mappings []FrequencyMapping
+	selected atomic.Pointer[string]
+}
+
+// NewTransverter creates a new Transverter with the given frequency mappings.
+func NewTransverter(mappings ...FrequencyMapping) *Transverter {
+	result := &Transverter{mappings: mappings}
+	result.selected.Store(new(string))
+	return result
+}
+
+// Mappings returns the frequency mappings of this transverter.
+func (t *Transverter) Mappings() []FrequencyMapping {
+	return t.mappings
+}
+
+// Select selects the mapping with the given name. An empty name deselects the currently selected mapping.
+func (t *Transverter) Select(name string) error {
+	if name == "" {
+		t.selected.Store(new(string))
+		return nil
+	}
+	for _, mapping := range t.mappings {
+		if mapping.Name != name {
+			continue
+		}
+		if !mapping.BandSwitch {
+			return fmt.Errorf("mapping %q has no band switch", name)
+		}
+		t.selected.Store(&name)
+		return nil
+	}
+	return fmt.Errorf("no mapping %q", name)
+}
+
+// Selected returns the name of the currently selected mapping or an empty string if no mapping is selected.
+func (t *Transverter) Selected() string {
+	return *t.selected.Load()
+}
+
+func (t *Transverter) active(mapping FrequencyMapping) bool {
+	return !mapping.BandSwitch || mapping.Name == t.Selected()
+}
+
+// ToRig translates the given on-air frequency into the frequency of the rig. If the frequency is inside the on-air
+// range of a mapping with band switch, the mapping is selected, otherwise the selected mapping is deselected.
+func (t *Transverter) ToRig(frequency int) int {
+	for _, mapping := range t.mappings {
+		if !mapping.airContains(frequency) {
+			continue
+		}
+		if mapping.BandSwitch {
+			t.selected.Store(&mapping.Name)
+		} else {
+			t.selected.Store(new(string))
+		}
+		return frequency - mapping.Offset
+	}
+	t.selected.Store(new(string))
+	return frequency
+}
+
+// toRigSplit translates the given on-air frequency into the frequency of the rig using the active mappings, without
+// changing the selection.
+func (t *Transverter) toRigSplit(frequency int) int {
+	for _, mapping := range t.mappings {
+		if t.active(mapping) && mapping.airContains(frequency) {
+			return frequency - mapping.Offset
+		}
+	}
+	return frequency
+}
+
+// ToAir translates the given frequency of the rig into the on-air frequency using the active mappings.
+func (t *Transverter) ToAir(frequency int) int {
+	for _, mapping := range t.mappings {
+		if t.active(mapping) && mapping.rigContains(frequency) {
+			return frequency + mapping.Offset
+		}
+	}
+	return frequency
+}
+
+// MapFrequencies rewrites the arguments of set_freq and set_split_freq and the responses of get_freq and
+// get_split_freq using the given Transverter, the clients only see the on-air frequencies. The on-air frequency
+// ranges of all mappings are added to the frequency ranges in the response of dump_state.
+func MapFrequencies(transverter *Transverter) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			switch req.Key() {
+			case "set_freq":
+				req = mapFrequencyArg(req, transverter.ToRig)
+			case "set_split_freq":
+				req = mapFrequencyArg(req, transverter.toRigSplit)
+			}
+
+			resp, err := next.Handle(ctx, req)
+			if err != nil || resp.Result != "0" {
+				return resp, err
+			}
+
+			switch req.Key() {
+			case "get_freq", "get_split_freq":
+				resp = mapFrequencyData(resp, transverter.ToAir)
+			case "dump_state":
+				resp = addOnAirRanges(resp, transverter.mappings)
+			}
+			return resp, nil
+		})
+	}
+}
+
+func parseFrequency(s string) (int, bool) {
+	frequency, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(frequency), true
+}
+
+func mapFrequencyArg(req protocol.Request, mapFrequency func(int) int) protocol.Request {
+	if len(req.Args) == 0 {
+		return req
+	}
+	frequency, ok := parseFrequency(req.Args[0])
+	if !ok {
+		return req
+	}
+	args := make([]string, len(req.Args))
+	copy(args, req.Args)
+	args[0] = strconv.Itoa(mapFrequency(frequency))
+	req.Args = args
+	return req
+}
+
+func mapFrequencyData(resp protocol.Response, mapFrequency func(int) int) protocol.Response {
+	if len(resp.Data) == 0 {
+		return resp
+	}
+	frequency, ok := parseFrequency(resp.Data[0])
+	if !ok {
+		return resp
+	}
+	data := make([]string, len(resp.Data))
+	copy(data, resp.Data)
+	data[0] = strconv.Itoa(mapFrequency(frequency))
+	resp.Data = data
+	return resp
+}
+
+// dumpStateRangesStart is the index of the first line of the RX frequency ranges in the response of dump_state. The
+// list of RX ranges is followed by the list of TX ranges, each list is terminated by a line of zeros.
+const dumpStateRangesStart = 3
+
+func addOnAirRanges(resp protocol.Response, mappings []FrequencyMapping) protocol.Response {
+	var data, keys []string
+	appendLine := func(i int) {
+		data = append(data, resp.Data[i])
+		if len(resp.Keys) > 0 {
+			keys = append(keys, resp.Keys[i])
+		}
+	}
+	appendRange := func(line string) {
+		data = append(data, line)
+		if len(resp.Keys) > 0 {
+			keys = append(keys, "")
+		}
+	}
+
+	lists := 0
+	var added []string
+	for i, line := range resp.Data {
+		if i < dumpStateRangesStart || lists == 2 {
+			appendLine(i)
+			continue
+		}
+		if isEndOfRanges(line) {
+			for _, r := range added {
+				appendRange(r)
+			}
+			added = nil
+			lists++
+			appendLine(i)
+			continue
+		}
+		appendLine(i)
+		added = append(added, onAirRanges(line, mappings)...)
+	}
+
+	resp.Data = data
+	if len(resp.Keys) > 0 {
+		resp.Keys = keys
+	}
+	return resp
+}
+
+func isEndOfRanges(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, field := range fields {
+		if field != "0" {
+			return false
+		}
+	}
+	return true
+}
+
+// onAirRanges returns the on-air frequency ranges that the given frequency range of the rig covers through the given
+// mappings.
+func onAirRanges(line string, mappings []FrequencyMapping) []string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil
+	}
+	start, ok := parseFrequency(fields[0])
+	if !ok {
+		return nil
+	}
+	end, ok := parseFrequency(fields[1])
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, mapping := range mappings {
+		from := max(start, mapping.From)
+		to := min(end, mapping.To)
+		if from > to {
+			continue
+		}
+		airRange := make([]string, len(fields))
+		copy(airRange, fields)
+		airRange[0] = fmt.Sprintf("%d.000000", from+mapping.Offset)
+		airRange[1] = fmt.Sprintf("%d.000000", to+mapping.Offset)
+		result = append(result, strings.Join(airRange, " "))
+	}
+	return result
+}
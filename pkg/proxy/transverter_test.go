@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+var twoMeters = FrequencyMapping{Name: "2m", From: 28000000, To: 30000000, Offset: 116000000, BandSwitch: true}
+var seventyCentimeters = FrequencyMapping{Name: "70cm", From: 144000000, To: 146000000, Offset: 288000000}
+
+func TestTransverterMapsFrequencies(t *testing.T) {
+	transverter := NewTransverter(twoMeters, seventyCentimeters)
+
+	testCases := []struct {
+		desc     string
+		selected string
+		rig      int
+		air      int
+	}{
+		{"without band switch", "", 144300000, 432300000},
+		{"band switch off", "", 28300000, 28300000},
+		{"band switch on", "2m", 28300000, 144300000},
+		{"outside of all mappings", "2m", 14074000, 14074000},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			require.NoError(t, transverter.Select(tC.selected))
+			assert.Equal(t, tC.air, transverter.ToAir(tC.rig))
+		})
+	}
+}
+
+func TestTransverterSelectsBandWhenTuning(t *testing.T) {
+	transverter := NewTransverter(twoMeters, seventyCentimeters)
+
+	assert.Equal(t, 28300000, transverter.ToRig(144300000))
+	assert.Equal(t, "2m", transverter.Selected())
+	assert.Equal(t, 144100000, transverter.ToRig(432100000))
+	assert.Equal(t, "", transverter.Selected())
+
+	transverter.ToRig(144300000)
+	assert.Equal(t, 28500000, transverter.ToRig(28500000), "direct operation on 10m")
+	assert.Equal(t, "", transverter.Selected())
+}
+
+func TestTransverterSelect(t *testing.T) {
+	transverter := NewTransverter(twoMeters, seventyCentimeters)
+
+	assert.NoError(t, transverter.Select("2m"))
+	assert.Equal(t, "2m", transverter.Selected())
+	assert.Error(t, transverter.Select("70cm"), "no band switch")
+	assert.Error(t, transverter.Select("23cm"), "unknown")
+	assert.NoError(t, transverter.Select(""))
+	assert.Equal(t, "", transverter.Selected())
+}
+
+func TestProxyMapsFrequencies(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:         trx,
+		cache:       cache.New(),
+		transverter: NewTransverter(twoMeters),
+	}
+	trx.On("Send", mock.Anything, mock.MatchedBy(func(req protocol.Request) bool {
+		return req.Long == "set_freq" && req.Args[0] == "28300000"
+	})).Once().Return(protocol.OKResponse("set_freq"), nil)
+	trx.On("Send", mock.Anything, isRequest("get_freq")).Once().Return(protocol.GetFreqResponse(28300000), nil)
+	trx.On("Send", mock.Anything, mock.MatchedBy(func(req protocol.Request) bool {
+		return req.Long == "set_split_freq" && req.Args[0] == "28310000"
+	})).Once().Return(protocol.OKResponse("set_split_freq"), nil)
+	trx.On("Send", mock.Anything, isRequest("get_split_freq")).Once().Return(protocol.GetSplitFreqResponse(28310000), nil)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_freq"), Args: []string{"144300000"}})
+	require.NoError(t, err)
+	freq, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_freq")})
+	require.NoError(t, err)
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_split_freq"), Args: []string{"144310000"}})
+	require.NoError(t, err)
+	splitFreq, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("get_split_freq")})
+	require.NoError(t, err)
+
+	assert.Equal(t, protocol.GetFreqResponse(144300000), freq)
+	assert.Equal(t, protocol.GetSplitFreqResponse(144310000), splitFreq)
+	cached, _ := proxy.cache.Get("get_freq")
+	assert.Equal(t, protocol.GetFreqResponse(28300000), cached, "the cache contains the rig frequency")
+	trx.AssertExpectations(t)
+}
+
+func TestProxyAddsOnAirRangesToDumpState(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:         trx,
+		cache:       new(nopCache),
+		transverter: NewTransverter(twoMeters),
+	}
+	dumpState := protocol.Response{
+		Command: "dump_state",
+		Data: []string{
+			"1", "3073", "0",
+			"100000.000000 74800000.000000 0x1ff -1 -1 0x10000003 0x3",
+			"0 0 0 0 0 0 0",
+			"1800000.000000 1999999.000000 0x1be 5000 100000 0x10000003 0x3",
+			"28000000.000000 29699999.000000 0x1be 5000 100000 0x10000003 0x3",
+			"0 0 0 0 0 0 0",
+			"0x1ff 1",
+		},
+		Keys:   []string{"", "", "", "", "", "", "", "", ""},
+		Result: "0",
+	}
+	trx.On("Send", mock.Anything, isRequest("dump_state")).Once().Return(dumpState, nil)
+
+	actual, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("dump_state")})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"1", "3073", "0",
+		"100000.000000 74800000.000000 0x1ff -1 -1 0x10000003 0x3",
+		"144000000.000000 146000000.000000 0x1ff -1 -1 0x10000003 0x3",
+		"0 0 0 0 0 0 0",
+		"1800000.000000 1999999.000000 0x1be 5000 100000 0x10000003 0x3",
+		"28000000.000000 29699999.000000 0x1be 5000 100000 0x10000003 0x3",
+		"144000000.000000 145699999.000000 0x1be 5000 100000 0x10000003 0x3",
+		"0 0 0 0 0 0 0",
+		"0x1ff 1",
+	}, actual.Data)
+	assert.Len(t, actual.Keys, len(actual.Data))
+}
@@ -0,0 +1,163 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+// LicenseClass defines where a license class is allowed to transmit. Bands contains the allocated bands of the
+// bandplan, all bands of the bandplan are allowed if Bands is empty. Ranges contains additional allocations that are
+// not covered by the bandplan, e.g. VHF and UHF bands, all modes are allowed within these ranges. With ModeSegments,
+// transmissions must also respect the mode segments of the bandplan.
+type LicenseClass struct {
+	Name         string
+	Bands        []bandplan.BandName
+	Ranges       []hamradio.FrequencyRange
+	ModeSegments bool
+}
+
+// TXGuard checks if a transmission on a frequency in a mode is allowed for a license class. Within the mode
+// segments of the bandplan, CW is allowed in all segments, digital modes are allowed in the digital and phone
+// segments, phone is only allowed in the phone segments. Beacon segments are excluded.
+type TXGuard struct {
+	bandplan bandplan.Bandplan
+	license  LicenseClass
+	bands    map[bandplan.BandName]bool
+}
+
+// NewTXGuard creates a new TXGuard for the given bandplan and license class.
+func NewTXGuard(plan bandplan.Bandplan, license LicenseClass) *TXGuard {
+	bands := make(map[bandplan.BandName]bool, len(license.Bands))
+	for _, band := range license.Bands {
+		bands[band] = true
+	}
+	return &TXGuard{
+		bandplan: plan,
+		license:  license,
+		bands:    bands,
+	}
+}
+
+// Check returns an error if a transmission on the given frequency in the given mode is not allowed.
+func (g *TXGuard) Check(frequency hamradio.Frequency, mode bandplan.Mode) error {
+	for _, r := range g.license.Ranges {
+		if r.Contains(frequency) {
+			return nil
+		}
+	}
+
+	band := g.bandplan.ByFrequency(frequency)
+	if band.Name == bandplan.BandUnknown || (len(g.bands) > 0 && !g.bands[band.Name]) {
+		return fmt.Errorf("%w: %.0fHz is outside of the allocations of license class %s", protocol.ErrCommandRejectedByRig, frequency, g.license.Name)
+	}
+	if g.license.ModeSegments && !modeAllowed(band, frequency, mode) {
+		return fmt.Errorf("%w: %s is not allowed on %.0fHz", protocol.ErrCommandRejectedByRig, mode, frequency)
+	}
+	return nil
+}
+
+func modeAllowed(band bandplan.Band, frequency hamradio.Frequency, mode bandplan.Mode) bool {
+	for _, portion := range band.Portions {
+		if !portion.Contains(frequency) {
+			continue
+		}
+		switch portion.Mode {
+		case bandplan.ModeCW:
+			if mode == bandplan.ModeCW {
+				return true
+			}
+		case bandplan.ModeDigital:
+			if mode == bandplan.ModeCW || mode == bandplan.ModeDigital {
+				return true
+			}
+		case bandplan.ModePhone:
+			return true
+		}
+	}
+	return false
+}
+
+// GuardTransmission refuses set_ptt, send_morse and send_voice_mem with RPRT -9 if the given TXGuard does not allow
+// a transmission on the current transmit frequency in the current mode. send_morse is always checked as CW
+// transmission. The frequency and the mode are requested from the next handler, usually they are answered from the
+// cache. If they cannot be determined, the transmission is refused.
+func GuardTransmission(guard *TXGuard) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req protocol.Request) (protocol.Response, error) {
+			switch req.Key() {
+			case "set_ptt":
+				if len(req.Args) > 0 && req.Args[0] == "0" {
+					return next.Handle(ctx, req)
+				}
+			case "send_morse", "send_voice_mem":
+			default:
+				return next.Handle(ctx, req)
+			}
+
+			frequency, mode, err := transmitState(ctx, next)
+			if err != nil {
+				loggerFromContext(ctx).Warn("transmission refused", "command", req.Key(), "error", err)
+				return protocol.Response{}, fmt.Errorf("%w: cannot determine the transmit frequency: %v", protocol.ErrCommandRejectedByRig, err)
+			}
+
+			bandplanMode := protocol.BandplanMode(mode)
+			if req.Key() == protocol.CommandKey("send_morse") {
+				bandplanMode = bandplan.ModeCW
+			}
+			if err := guard.Check(frequency, bandplanMode); err != nil {
+				loggerFromContext(ctx).Warn("transmission refused", "command", req.Key(), "frequency", int(frequency), "mode", mode, "error", err)
+				return protocol.Response{}, err
+			}
+			return next.Handle(ctx, req)
+		})
+	}
+}
+
+// transmitState returns the frequency and the mode that are used for transmitting, taking split operation into
+// account.
+func transmitState(ctx context.Context, next Handler) (hamradio.Frequency, string, error) {
+	split, err := query(ctx, next, "get_split_vfo")
+	if err != nil {
+		return 0, "", err
+	}
+	freqCommand, modeCommand := "get_freq", "get_mode"
+	if split.Data[0] == "1" {
+		freqCommand, modeCommand = "get_split_freq", "get_split_mode"
+	}
+
+	freq, err := query(ctx, next, freqCommand)
+	if err != nil {
+		return 0, "", err
+	}
+	frequency, err := strconv.ParseFloat(freq.Data[0], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid frequency %q: %w", freq.Data[0], err)
+	}
+
+	mode, err := query(ctx, next, modeCommand)
+	if err != nil {
+		return 0, "", err
+	}
+	return hamradio.Frequency(frequency), mode.Data[0], nil
+}
+
+func query(ctx context.Context, next Handler, command string) (protocol.Response, error) {
+	req := protocol.Request{Command: protocol.LongCommand(command)}
+	resp, err := next.Handle(ctx, req)
+	if err == nil {
+		err = protocol.ResultError(req.Key(), resp.Result)
+	}
+	if err != nil {
+		return protocol.Response{}, err
+	}
+	if len(resp.Data) == 0 {
+		return protocol.Response{}, fmt.Errorf("%w: empty response to %s", protocol.ErrProtocolError, command)
+	}
+	return resp, nil
+}
@@ -0,0 +1,124 @@
+package proxy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ftl/hamradio"
+	"github.com/ftl/hamradio/bandplan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ftl/rigproxy/pkg/cache"
+	"github.com/ftl/rigproxy/pkg/protocol"
+)
+
+func TestTXGuardCheck(t *testing.T) {
+	guard := NewTXGuard(bandplan.IARURegion1, LicenseClass{
+		Name:         "E",
+		Bands:        []bandplan.BandName{bandplan.Band80m, bandplan.Band20m},
+		Ranges:       []hamradio.FrequencyRange{{From: 144000000, To: 146000000}},
+		ModeSegments: true,
+	})
+
+	testCases := []struct {
+		desc      string
+		frequency hamradio.Frequency
+		mode      bandplan.Mode
+		allowed   bool
+	}{
+		{"cw in cw segment", 14025000, bandplan.ModeCW, true},
+		{"cw in phone segment", 14250000, bandplan.ModeCW, true},
+		{"digital in digital segment", 14074000, bandplan.ModeDigital, true},
+		{"digital in phone segment", 14250000, bandplan.ModeDigital, true},
+		{"phone in phone segment", 14250000, bandplan.ModePhone, true},
+		{"phone in cw segment", 14025000, bandplan.ModePhone, false},
+		{"digital in cw segment", 14025000, bandplan.ModeDigital, false},
+		{"beacon segment", 14100000, bandplan.ModeCW, false},
+		{"band not allocated", 7074000, bandplan.ModeDigital, false},
+		{"outside of all bands", 14400000, bandplan.ModeCW, false},
+		{"additional range", 144300000, bandplan.ModePhone, true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := guard.Check(tC.frequency, tC.mode)
+			if tC.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, protocol.ErrCommandRejectedByRig)
+			}
+		})
+	}
+}
+
+func TestTXGuardWithoutBandsAllowsAllBandsOfTheBandplan(t *testing.T) {
+	guard := NewTXGuard(bandplan.IARURegion1, LicenseClass{Name: "A"})
+
+	assert.NoError(t, guard.Check(7074000, bandplan.ModePhone))
+	assert.NoError(t, guard.Check(50313000, bandplan.ModeDigital))
+	assert.Error(t, guard.Check(144300000, bandplan.ModePhone))
+}
+
+func TestProxyGuardsTransmission(t *testing.T) {
+	trx := new(mockTransceiver)
+	c := cache.New()
+	c.Put("get_split_vfo", protocol.GetSplitVFOResponse(false, "VFOA"))
+	c.Put("get_freq", protocol.GetFreqResponse(14025000))
+	c.Put("get_mode", protocol.GetModeResponse("USB", 2700))
+	proxy := Proxy{
+		trx:     trx,
+		cache:   c,
+		txGuard: NewTXGuard(bandplan.IARURegion1, LicenseClass{Name: "A", ModeSegments: true}),
+	}
+	trx.On("Send", mock.Anything, isRequest("set_ptt")).Once().Return(protocol.OKResponse("set_ptt"), nil)
+	trx.On("Send", mock.Anything, isRequest("send_morse")).Once().Return(protocol.OKResponse("send_morse"), nil)
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_ptt"), Args: []string{"1"}})
+	assert.ErrorIs(t, err, protocol.ErrCommandRejectedByRig)
+	assert.Equal(t, -9, protocol.ErrorCode(err))
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("send_voice_mem"), Args: []string{"1"}})
+	assert.ErrorIs(t, err, protocol.ErrCommandRejectedByRig)
+
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_ptt"), Args: []string{"0"}})
+	assert.NoError(t, err, "ptt off is always allowed")
+	_, err = proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("send_morse"), Args: []string{"CQ"}})
+	assert.NoError(t, err, "cw is allowed in the cw segment")
+	trx.AssertExpectations(t)
+}
+
+func TestProxyGuardUsesSplitFrequency(t *testing.T) {
+	trx := new(mockTransceiver)
+	c := cache.New()
+	c.Put("get_split_vfo", protocol.GetSplitVFOResponse(true, "VFOB"))
+	c.Put("get_freq", protocol.GetFreqResponse(14195000))
+	c.Put("get_mode", protocol.GetModeResponse("USB", 2700))
+	c.Put("get_split_freq", protocol.GetSplitFreqResponse(14400000))
+	c.Put("get_split_mode", protocol.GetSplitModeResponse("USB", 2700))
+	proxy := Proxy{
+		trx:     trx,
+		cache:   c,
+		txGuard: NewTXGuard(bandplan.IARURegion1, LicenseClass{Name: "A"}),
+	}
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_ptt"), Args: []string{"1"}})
+
+	assert.ErrorIs(t, err, protocol.ErrCommandRejectedByRig)
+	trx.AssertNotCalled(t, "Send")
+}
+
+func TestProxyGuardRefusesTransmissionWithUnknownFrequency(t *testing.T) {
+	trx := new(mockTransceiver)
+	proxy := Proxy{
+		trx:     trx,
+		cache:   cache.New(),
+		txGuard: NewTXGuard(bandplan.IARURegion1, LicenseClass{Name: "A"}),
+	}
+	trx.On("Send", mock.Anything, isRequest("get_split_vfo")).Once().Return(protocol.Response{}, errors.New("timeout"))
+
+	_, err := proxy.handleRequest(protocol.Request{Command: protocol.LongCommand("set_ptt"), Args: []string{"1"}})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, protocol.ErrCommandRejectedByRig)
+	trx.AssertExpectations(t)
+}
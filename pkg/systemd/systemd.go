@@ -0,0 +1,115 @@
+// Package systemd implements the parts of the systemd service protocol that are used by rigproxy: socket activation
+// through LISTEN_FDS and service notifications through NOTIFY_SOCKET.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd, see sd_listen_fds(3).
+const listenFDsStart = 3
+
+// The states that can be sent with Notify, see sd_notify(3).
+const (
+	Ready     = "READY=1"
+	Reloading = "RELOADING=1"
+	Stopping  = "STOPPING=1"
+	Watchdog  = "WATCHDOG=1"
+)
+
+// Status returns the state that describes the service status with the given text.
+func Status(text string) string {
+	return "STATUS=" + text
+}
+
+// Listeners returns the listeners that were passed to this process by systemd socket activation, grouped by the
+// name of the file descriptor (FileDescriptorName= in the socket unit, the name of the socket unit by default).
+// The LISTEN_* environment variables are unset, so the listeners are not passed on to child processes. If the
+// process was not socket activated, Listeners returns an empty map.
+func Listeners() (map[string][]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return map[string][]net.Listener{}, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LISTEN_FDS: %w", err)
+	}
+
+	names := listenerNames(count, os.Getenv("LISTEN_FDNAMES"))
+	result := make(map[string][]net.Listener, count)
+	for i := 0; i < count; i++ {
+		fd := listenFDsStart + i
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), names[i])
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot use file descriptor %d (%s) as listener: %w", fd, names[i], err)
+		}
+		result[names[i]] = append(result[names[i]], l)
+	}
+	return result, nil
+}
+
+func listenerNames(count int, fdNames string) []string {
+	result := make([]string, count)
+	names := strings.Split(fdNames, ":")
+	for i := range result {
+		if i < len(names) && names[i] != "" {
+			result[i] = names[i]
+		} else {
+			result[i] = "unknown"
+		}
+	}
+	return result
+}
+
+// Notify sends the given states to the service manager. It returns false if the process is not run by systemd as a
+// notify service, i.e. NOTIFY_SOCKET is not set.
+func Notify(states ...string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the interval in which the service manager expects WATCHDOG=1 notifications. It returns
+// false if the watchdog is not enabled for this process.
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pidValue := os.Getenv("WATCHDOG_PID"); pidValue != "" {
+		pid, err := strconv.Atoi(pidValue)
+		if err != nil || pid != os.Getpid() {
+			return 0, false
+		}
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
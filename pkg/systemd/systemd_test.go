@@ -0,0 +1,84 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+
+	sent, err := Notify(Ready, Status("connected"))
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	buffer := make([]byte, 1024)
+	n, err := conn.Read(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=connected", string(buffer[:n]))
+}
+
+func TestNotifyWithoutSocket(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	sent, err := Notify(Ready)
+
+	assert.NoError(t, err)
+	assert.False(t, sent)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		usec     string
+		pid      string
+		expected time.Duration
+		enabled  bool
+	}{
+		{"disabled", "", "", 0, false},
+		{"enabled", "30000000", "", 30 * time.Second, true},
+		{"this process", "30000000", strconv.Itoa(os.Getpid()), 30 * time.Second, true},
+		{"other process", "30000000", "1", 0, false},
+		{"invalid", "soon", "", 0, false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tC.usec)
+			t.Setenv("WATCHDOG_PID", tC.pid)
+
+			actual, enabled := WatchdogInterval()
+
+			assert.Equal(t, tC.expected, actual)
+			assert.Equal(t, tC.enabled, enabled)
+		})
+	}
+}
+
+func TestListenersWithoutActivation(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "2")
+
+	actual, err := Listeners()
+
+	require.NoError(t, err)
+	assert.Empty(t, actual)
+	_, ok := os.LookupEnv("LISTEN_FDS")
+	assert.False(t, ok)
+}
+
+func TestListenerNames(t *testing.T) {
+	assert.Equal(t, []string{"rig", "rotator"}, listenerNames(2, "rig:rotator"))
+	assert.Equal(t, []string{"rig", "unknown"}, listenerNames(2, "rig"))
+	assert.Equal(t, []string{"unknown"}, listenerNames(1, ""))
+}